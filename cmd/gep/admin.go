@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mk990/gep/pkg/prox"
+)
+
+// defaultAdminAddr is where gep serve's admin control endpoint listens by
+// default, and where gep status/reload look for it when -admin-addr is
+// not given.
+const defaultAdminAddr = "127.0.0.1:8889"
+
+// adminStatus is the JSON shape served at /status and /reload.
+type adminStatus struct {
+	Healthy int `json:"healthy"`
+	Total   int `json:"total"`
+}
+
+// startAdminServer serves gep serve's control surface on addr: GET
+// /status reports pool health, POST /reload calls reload to re-read and
+// re-validate the proxy list, replacing pool's contents.
+func startAdminServer(addr string, pool *prox.Pool, reload func() (healthy, total int, err error)) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminStatus(w, len(pool.Healthy()), len(pool.All()))
+	})
+	mux.HandleFunc("/reload", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		healthy, total, err := reload()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeAdminStatus(w, healthy, total)
+	})
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			fmt.Fprintf(os.Stderr, "gep serve: admin listener error: %v\n", err)
+		}
+	}()
+	fmt.Printf("Admin control listening on %s (status, reload)\n", addr)
+}
+
+func writeAdminStatus(w http.ResponseWriter, healthy, total int) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(adminStatus{Healthy: healthy, Total: total})
+}
+
+// fetchAdminStatus calls path on the admin endpoint at addr (GET, unless
+// method is given) and decodes the resulting adminStatus.
+func fetchAdminStatus(addr, path, method string) (adminStatus, error) {
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("http://%s%s", addr, path), nil)
+	if err != nil {
+		return adminStatus{}, err
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return adminStatus{}, fmt.Errorf("connecting to gep serve's admin endpoint at %s: %w (is it running with -admin-addr %s?)", addr, err, addr)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return adminStatus{}, fmt.Errorf("%s: HTTP %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var status adminStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return adminStatus{}, fmt.Errorf("decoding response from %s: %w", path, err)
+	}
+	return status, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mk990/gep/pkg/prox"
+)
+
+func runHistory(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+
+	var dbPath string
+	var since time.Duration
+	var workingOnly bool
+	var country string
+
+	fs.StringVar(&dbPath, "db", "", "Path to the SQLite proxy-history database (default ~/.gep/gep.db)")
+	fs.DurationVar(&since, "since", 0, "Only show checks recorded within this long ago, e.g. 24h (default: all time)")
+	fs.BoolVar(&workingOnly, "working", false, "Only show checks that succeeded")
+	fs.StringVar(&country, "country", "", "Only show checks whose GeoIP country matches (case-insensitive)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s history [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Search the recorded history of past proxy checks.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s history --since 24h --working --country=DE\n", os.Args[0])
+	}
+	fs.Parse(args)
+
+	store, err := prox.NewSQLiteStore(dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gep history: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	filter := prox.HistoryFilter{
+		WorkingOnly: workingOnly,
+		Country:     strings.TrimSpace(country),
+	}
+	if since > 0 {
+		filter.Since = time.Now().Add(-since)
+	}
+
+	checks, err := store.History(context.Background(), filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gep history: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(checks) == 0 {
+		fmt.Fprintln(os.Stderr, "No recorded checks matched the requested filters")
+		return
+	}
+
+	fmt.Printf("%-20s %-50s %-10s %-15s %-12s %-7s\n",
+		"CHECKED AT", "PROXY", "STATUS", "IP", "ANONYMITY", "COUNTRY")
+	fmt.Println(strings.Repeat("-", 120))
+	for _, check := range checks {
+		status := "FAILED"
+		if check.IsWorking {
+			status = "WORKING"
+		}
+		fmt.Printf("%-20s %-50s %-10s %-15s %-12s %-7s\n",
+			check.CheckedAt.Local().Format("2006-01-02 15:04:05"),
+			check.Proxy, status, check.IP, check.Anonymity, check.Country)
+	}
+	fmt.Printf("\n%d checks matched\n", len(checks))
+}
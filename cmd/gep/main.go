@@ -0,0 +1,55 @@
+// Command gep is a concurrent proxy tester and (eventually) rotating proxy
+// gateway, built on top of the pkg/prox library.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const version = "2.0.0"
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "test":
+		runTest(os.Args[2:])
+	case "serve":
+		runServe(os.Args[2:])
+	case "reload":
+		runReload(os.Args[2:])
+	case "status":
+		runStatus(os.Args[2:])
+	case "history":
+		runHistory(os.Args[2:])
+	case "recheck":
+		runRecheck(os.Args[2:])
+	case "-v", "--version", "version":
+		fmt.Printf("gep %s\n", version)
+	case "-h", "--help", "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "gep: unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: gep <command> [options]
+
+Commands:
+  test      Check a list of proxies and report which ones work
+  serve     Run gep as a long-lived rotating proxy gateway
+  reload    Ask a running gep serve instance to reload its proxy list
+  status    Report the status of a running gep serve instance
+  history   Search the recorded history of past proxy checks
+  recheck   Re-test recorded proxies not checked recently
+
+Run "gep <command> -h" for command-specific options.
+`)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mk990/gep/pkg/prox"
+)
+
+func runRecheck(args []string) {
+	fs := flag.NewFlagSet("recheck", flag.ExitOnError)
+
+	var dbPath string
+	var stale time.Duration
+	var workers int
+	var timeout int
+	var checkURL string
+	var headerURL string
+
+	fs.StringVar(&dbPath, "db", "", "Path to the SQLite proxy-history database (default ~/.gep/gep.db)")
+	fs.DurationVar(&stale, "stale", time.Hour, "Re-test proxies whose most recent check is older than this")
+	fs.IntVar(&workers, "w", 10, "Number of concurrent workers")
+	fs.IntVar(&timeout, "t", 15, "Timeout in seconds per proxy")
+	fs.StringVar(&checkURL, "check-url", "", "URL to fetch through each proxy (default: http://ifconfig.co/ip)")
+	fs.StringVar(&headerURL, "header-url", "", "URL that echoes request headers, used for anonymity classification (default: http://httpbin.org/headers)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s recheck [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Re-test every recorded proxy not checked recently, and record the result.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s recheck --stale 1h\n", os.Args[0])
+	}
+	fs.Parse(args)
+
+	store, err := prox.NewSQLiteStore(dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gep recheck: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	proxies, err := store.StaleProxies(ctx, time.Now().Add(-stale))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gep recheck: %v\n", err)
+		os.Exit(1)
+	}
+	if len(proxies) == 0 {
+		fmt.Fprintln(os.Stderr, "No proxies are stale; nothing to recheck")
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Rechecking %d stale proxies with %d workers (timeout: %ds)...\n",
+		len(proxies), workers, timeout)
+
+	realIP, err := prox.FetchRealIP(ctx, checkURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not determine real IP, anonymity will not be classified: %v\n", err)
+	}
+
+	checker := prox.NewHTTPChecker(checkURL)
+	checker.Timeout = time.Duration(timeout) * time.Second
+	checker.HeaderURL = headerURL
+	checker.RealIP = realIP
+
+	tester := prox.NewTester(time.Duration(timeout)*time.Second, workers, checker)
+
+	working := 0
+	for result := range tester.TestProxiesStream(proxies) {
+		if result.IsWorking {
+			working++
+		}
+		if err := store.Record(ctx, result, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "gep recheck: recording %s: %v\n", result.Proxy, err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "\nSummary: %d/%d proxies working (%.1f%%)\n",
+		working, len(proxies), float64(working)/float64(len(proxies))*100)
+}
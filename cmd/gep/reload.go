@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+func runReload(args []string) {
+	fs := flag.NewFlagSet("reload", flag.ExitOnError)
+
+	var adminAddr string
+	fs.StringVar(&adminAddr, "admin-addr", defaultAdminAddr, "Admin address of the running gep serve instance")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s reload [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Ask a running gep serve instance to reload its proxy list.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	status, err := fetchAdminStatus(adminAddr, "/reload", http.MethodPost)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gep reload: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Reloaded: %d/%d proxies healthy\n", status.Healthy, status.Total)
+}
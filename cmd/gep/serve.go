@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mk990/gep/pkg/prox"
+)
+
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+
+	var proxyFile string
+	var addr string
+	var socks5Addr string
+	var strategy string
+	var maxFailures int
+	var revalidate time.Duration
+	var workers int
+	var timeout int
+	var htpasswdFile string
+	var mitm bool
+	var checkURL string
+	var metricsAddr string
+	var rateLimitQPS float64
+	var adminAddr string
+
+	fs.StringVar(&proxyFile, "l", "", "Load proxies from file")
+	fs.StringVar(&proxyFile, "list", "", "Load proxies from file (same as -l)")
+	fs.StringVar(&addr, "addr", ":8888", "HTTP(S) proxy listen address")
+	fs.StringVar(&socks5Addr, "socks5-addr", "", "SOCKS5 listen address (disabled if empty)")
+	fs.StringVar(&strategy, "strategy", string(prox.StrategyRoundRobin),
+		"Upstream selection strategy: round-robin, random, least-latency, sticky-by-client-ip")
+	fs.IntVar(&maxFailures, "max-failures", 3, "Consecutive failed checks before an upstream is evicted")
+	fs.DurationVar(&revalidate, "revalidate", 5*time.Minute, "Interval between background upstream re-checks")
+	fs.IntVar(&workers, "w", 10, "Number of concurrent workers for checks")
+	fs.IntVar(&timeout, "t", 15, "Timeout in seconds per proxy check")
+	fs.StringVar(&htpasswdFile, "htpasswd", "", "htpasswd file requiring Basic-Auth on the local listener")
+	fs.BoolVar(&mitm, "mitm", false, "MITM HTTPS CONNECT tunnels with a generated CA, to classify responses")
+	fs.StringVar(&checkURL, "check-url", "", "URL to fetch through each proxy during validation (default: http://ifconfig.co/ip)")
+	fs.StringVar(&metricsAddr, "metrics-addr", "", "Expose Prometheus metrics on this address (disabled if empty)")
+	fs.Float64Var(&rateLimitQPS, "rate-limit-qps", 0, "Max requests per second to the check-url host, across validation and revalidation (disabled if 0)")
+	fs.StringVar(&adminAddr, "admin-addr", defaultAdminAddr, "Admin address serving status/reload control for the gep status/reload commands (disable with empty string)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s serve -l proxies.txt [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Run gep as a long-lived rotating proxy gateway.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	if proxyFile == "" {
+		fmt.Fprintln(os.Stderr, "gep serve: -l/--list is required")
+		os.Exit(1)
+	}
+
+	proxies, err := (prox.FileLoader{Path: proxyFile}).Load(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gep serve: error loading proxies: %v\n", err)
+		os.Exit(1)
+	}
+	if len(proxies) == 0 {
+		fmt.Fprintln(os.Stderr, "gep serve: no proxies to serve")
+		os.Exit(1)
+	}
+
+	var metrics *prox.Metrics
+	if metricsAddr != "" {
+		metrics = prox.NewMetrics()
+		startMetricsServer(metricsAddr, metrics)
+	}
+
+	checker := prox.NewHTTPChecker(checkURL)
+	checker.Timeout = time.Duration(timeout) * time.Second
+	if rateLimitQPS > 0 {
+		checker.RateLimiter = prox.NewHostRateLimiter(rateLimitQPS)
+	}
+	tester := prox.NewTester(time.Duration(timeout)*time.Second, workers, checker)
+	tester.Metrics = metrics
+
+	pool := prox.NewPool(prox.Strategy(strategy), maxFailures)
+	fmt.Printf("Validating %d proxies before serving...\n", len(proxies))
+	for _, result := range tester.TestProxies(proxies) {
+		pool.ReportResult(result)
+	}
+	healthy := pool.Healthy()
+	if len(healthy) == 0 {
+		fmt.Fprintln(os.Stderr, "gep serve: no healthy proxies; aborting")
+		os.Exit(1)
+	}
+	fmt.Printf("%d/%d proxies are healthy\n", len(healthy), len(proxies))
+	if metrics != nil {
+		metrics.SetWorking(len(healthy))
+	}
+
+	gateway := prox.NewGateway(pool)
+
+	if htpasswdFile != "" {
+		auth, err := prox.LoadHtpasswd(htpasswdFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gep serve: error loading htpasswd file: %v\n", err)
+			os.Exit(1)
+		}
+		gateway.Auth = auth
+	}
+
+	if mitm {
+		ca, err := prox.NewCertAuthority(2)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gep serve: error generating MITM CA: %v\n", err)
+			os.Exit(1)
+		}
+		gateway.CA = ca
+		if err := os.WriteFile("gep-mitm-ca.pem", ca.RootCertPEM(), 0o644); err == nil {
+			fmt.Println("MITM CA certificate written to gep-mitm-ca.pem — import and trust it on clients")
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	revalidator := prox.NewRevalidator(pool, tester, revalidate)
+	revalidator.Metrics = metrics
+	go revalidator.Run(ctx)
+
+	if adminAddr != "" {
+		startAdminServer(adminAddr, pool, func() (int, int, error) {
+			proxies, healthy, err := reloadProxies(proxyFile, tester, pool)
+			if err != nil {
+				return 0, 0, err
+			}
+			gateway.PruneTransports(proxies)
+			return healthy, len(proxies), nil
+		})
+	}
+
+	httpServer := &http.Server{Addr: addr, Handler: gateway}
+	go func() {
+		fmt.Printf("HTTP(S) proxy listening on %s\n", addr)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "gep serve: HTTP listener error: %v\n", err)
+		}
+	}()
+
+	var socks5Listener net.Listener
+	if socks5Addr != "" {
+		socks5Listener, err = net.Listen("tcp", socks5Addr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gep serve: error starting SOCKS5 listener: %v\n", err)
+			os.Exit(1)
+		}
+		socks5Server := prox.NewSOCKS5Server(gateway.Dial)
+		socks5Server.Auth = gateway.Auth
+		go func() {
+			fmt.Printf("SOCKS5 proxy listening on %s\n", socks5Addr)
+			if err := socks5Server.Serve(socks5Listener); err != nil {
+				fmt.Fprintf(os.Stderr, "gep serve: SOCKS5 listener error: %v\n", err)
+			}
+		}()
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	<-sigCh
+
+	fmt.Println("\nShutting down...")
+	cancel()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	httpServer.Shutdown(shutdownCtx)
+	if socks5Listener != nil {
+		socks5Listener.Close()
+	}
+	prox.CloseSSHClients()
+}
+
+// reloadProxies re-reads proxyFile and re-validates every proxy in it
+// with tester before touching pool at all, so pool.Reset and the
+// ReportResult calls that follow it run back-to-back instead of leaving
+// a multi-second window where freshly reset (and so provisionally
+// healthy) but not-yet-checked upstreams could be handed out to live
+// requests. It returns the reloaded proxy list, for the caller to prune
+// any per-upstream state keyed on upstreams no longer in it.
+func reloadProxies(proxyFile string, tester *prox.Tester, pool *prox.Pool) (proxies []string, healthy int, err error) {
+	proxies, err = (prox.FileLoader{Path: proxyFile}).Load(context.Background())
+	if err != nil {
+		return nil, 0, fmt.Errorf("loading %s: %w", proxyFile, err)
+	}
+	if len(proxies) == 0 {
+		return nil, 0, fmt.Errorf("no proxies in %s", proxyFile)
+	}
+
+	results := tester.TestProxies(proxies)
+
+	pool.Reset(proxies)
+	for _, result := range results {
+		pool.ReportResult(result)
+	}
+	return proxies, len(pool.Healthy()), nil
+}
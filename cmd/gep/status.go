@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func runStatus(args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+
+	var adminAddr string
+	fs.StringVar(&adminAddr, "admin-addr", defaultAdminAddr, "Admin address of the running gep serve instance")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s status [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Report the status of a running gep serve instance.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	status, err := fetchAdminStatus(adminAddr, "/status", "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gep status: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%d/%d proxies healthy\n", status.Healthy, status.Total)
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mk990/gep/pkg/prox"
+)
+
+func runTest(args []string) {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+
+	var proxyFile string
+	var workers int
+	var timeout int
+	var checkURL string
+	var checkURLs string
+	var headerURL string
+	var geoipCountryDB string
+	var geoipASNDB string
+	var countryFilter string
+	var anonymityFilter string
+	var outputFormat string
+	var outputFile string
+	var metricsAddr string
+	var rateLimitQPS float64
+	var minWorkers int
+	var jitter time.Duration
+	var dbPath string
+
+	fs.StringVar(&proxyFile, "l", "", "Load proxies from file")
+	fs.StringVar(&proxyFile, "list", "", "Load proxies from file (same as -l)")
+	fs.IntVar(&workers, "w", 10, "Number of concurrent workers")
+	fs.IntVar(&workers, "workers", 10, "Number of concurrent workers (same as -w)")
+	fs.IntVar(&timeout, "t", 15, "Timeout in seconds per proxy")
+	fs.IntVar(&timeout, "timeout", 15, "Timeout in seconds per proxy (same as -t)")
+	fs.StringVar(&checkURL, "check-url", "", "URL to fetch through each proxy (default: http://ifconfig.co/ip)")
+	fs.StringVar(&checkURLs, "check-urls", "", "Comma-separated check URLs, queried round-robin so no single one sees all traffic (overrides -check-url)")
+	fs.StringVar(&headerURL, "header-url", "", "URL that echoes request headers, used for anonymity classification (default: http://httpbin.org/headers)")
+	fs.StringVar(&geoipCountryDB, "geoip-country-db", "", "Path to a GeoLite2-Country .mmdb file, to populate Country (disabled if empty)")
+	fs.StringVar(&geoipASNDB, "geoip-asn-db", "", "Path to a GeoLite2-ASN .mmdb file, to populate ASN/Org (disabled if empty)")
+	fs.StringVar(&countryFilter, "country", "", "Only keep proxies whose GeoIP country is one of this comma-separated list (e.g. US,DE)")
+	fs.StringVar(&anonymityFilter, "anonymity", "", "Only keep proxies at this anonymity level: transparent, anonymous or elite")
+	fs.StringVar(&outputFormat, "output", "table", "Result format: table, json, jsonl or csv")
+	fs.StringVar(&outputFile, "o", "", "Write results to this file instead of stdout")
+	fs.StringVar(&metricsAddr, "metrics-addr", "", "Expose Prometheus metrics on this address (disabled if empty)")
+	fs.Float64Var(&rateLimitQPS, "rate-limit-qps", 0, "Max requests per second to any single check endpoint host (disabled if 0)")
+	fs.IntVar(&minWorkers, "min-workers", 1, "Floor the adaptive worker count is allowed to shrink to under throttling")
+	fs.DurationVar(&jitter, "jitter", 0, "Add a random delay up to this long before each check, to avoid lockstep request spacing")
+	fs.StringVar(&dbPath, "db", "", "Path to a SQLite proxy-history database; records every check, and is required to resolve a -l db:// source (default: ~/.gep/gep.db when a db:// source is used)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s test [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s test -l proxies.txt\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  cat proxies.txt | %s test\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s test -l proxies.txt -w 20 -t 10\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nProxy format: ipOrDomain:port (one per line)\n")
+	}
+
+	fs.Parse(args)
+
+	var countries []string
+	for _, c := range splitAndTrim(countryFilter) {
+		countries = append(countries, strings.ToUpper(c))
+	}
+	anonymity := prox.AnonymityLevel(strings.TrimSpace(anonymityFilter))
+	switch anonymity {
+	case "", prox.AnonymityTransparent, prox.AnonymityAnonymous, prox.AnonymityElite:
+	default:
+		fmt.Fprintf(os.Stderr, "gep test: invalid -anonymity %q (want transparent, anonymous or elite)\n", anonymityFilter)
+		os.Exit(1)
+	}
+
+	geoProvider, err := buildGeoProvider(geoipCountryDB, geoipASNDB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gep test: %v\n", err)
+		os.Exit(1)
+	}
+
+	var out io.Writer = os.Stdout
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gep test: creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		out = file
+	}
+	resultWriter, err := prox.NewResultWriter(out, prox.OutputFormat(outputFormat))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gep test: %v\n", err)
+		os.Exit(1)
+	}
+
+	var metrics *prox.Metrics
+	if metricsAddr != "" {
+		metrics = prox.NewMetrics()
+		startMetricsServer(metricsAddr, metrics)
+	}
+
+	var store prox.Store
+	if dbPath != "" || strings.HasPrefix(proxyFile, prox.DBLoaderScheme+"://") {
+		s, err := prox.NewSQLiteStore(dbPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gep test: %v\n", err)
+			os.Exit(1)
+		}
+		defer s.Close()
+		store = s
+	}
+
+	fmt.Fprintln(os.Stderr, "Concurrent Proxy Tester")
+	fmt.Fprintln(os.Stderr, "=======================")
+
+	var loader prox.Loader
+	if proxyFile != "" {
+		if strings.HasPrefix(proxyFile, prox.DBLoaderScheme+"://") {
+			fmt.Fprintf(os.Stderr, "Loading proxies from history database: %s\n", proxyFile)
+			loader = prox.DBLoader{Store: store, URL: proxyFile}
+		} else {
+			fmt.Fprintf(os.Stderr, "Loading proxies from file: %s\n", proxyFile)
+			loader = prox.FileLoader{Path: proxyFile}
+		}
+	} else if isStdinAvailable() {
+		fmt.Fprintln(os.Stderr, "Reading proxies from stdin...")
+		loader = prox.ReaderLoader{Reader: os.Stdin}
+	} else {
+		fmt.Fprintln(os.Stderr, "No proxy input provided.")
+		fmt.Fprintln(os.Stderr, "\nUsage examples:")
+		fmt.Fprintf(os.Stderr, "  %s test -l proxies.txt\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  cat proxies.txt | %s test\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  echo '192.168.1.1:8080' | %s test\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	proxies, err := loader.Load(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading proxies: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(proxies) == 0 {
+		fmt.Fprintln(os.Stderr, "No proxies to test!")
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "Testing %d proxies with %d workers (timeout: %ds)...\n",
+		len(proxies), workers, timeout)
+
+	realIP, err := prox.FetchRealIP(context.Background(), checkURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not determine real IP, anonymity will not be classified: %v\n", err)
+	}
+
+	checker := prox.NewHTTPChecker(checkURL)
+	checker.Timeout = time.Duration(timeout) * time.Second
+	checker.HeaderURL = headerURL
+	checker.RealIP = realIP
+	checker.GeoProvider = geoProvider
+	checker.URLs = splitAndTrim(checkURLs)
+	if rateLimitQPS > 0 {
+		checker.RateLimiter = prox.NewHostRateLimiter(rateLimitQPS)
+	}
+
+	tester := prox.NewTester(time.Duration(timeout)*time.Second, workers, checker)
+	tester.Metrics = metrics
+	tester.MinWorkers = minWorkers
+	tester.Jitter = jitter
+
+	var workingProxies []string
+	working := 0
+	for result := range tester.TestProxiesStream(proxies) {
+		if store != nil {
+			if err := store.Record(context.Background(), result, time.Now()); err != nil {
+				fmt.Fprintf(os.Stderr, "gep test: recording %s: %v\n", result.Proxy, err)
+			}
+		}
+		if !matchesFilters(result, countries, anonymity) {
+			continue
+		}
+		if result.IsWorking {
+			working++
+			workingProxies = append(workingProxies, result.Proxy)
+		}
+		if err := resultWriter.Write(result); err != nil {
+			fmt.Fprintf(os.Stderr, "gep test: writing result: %v\n", err)
+		}
+	}
+	if err := resultWriter.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "gep test: writing result: %v\n", err)
+	}
+	if metrics != nil {
+		metrics.SetWorking(working)
+	}
+
+	if len(workingProxies) > 0 {
+		file, err := os.Create("working_proxies.txt")
+		if err == nil {
+			defer file.Close()
+			for _, proxy := range workingProxies {
+				file.WriteString(proxy + "\n")
+			}
+			fmt.Fprintf(os.Stderr, "\nWorking proxies saved to: working_proxies.txt\n")
+		}
+	}
+}
+
+// startMetricsServer serves metrics at /metrics on addr in the
+// background. Listener errors are reported but do not abort the test
+// run already in progress.
+func startMetricsServer(addr string, metrics *prox.Metrics) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			fmt.Fprintf(os.Stderr, "gep test: metrics listener error: %v\n", err)
+		}
+	}()
+	fmt.Fprintf(os.Stderr, "Metrics listening on %s/metrics\n", addr)
+}
+
+// buildGeoProvider wires up GeoIP enrichment from whichever of
+// countryDB/asnDB the caller configured. It returns a nil GeoProvider
+// (disabling enrichment) when neither is set.
+func buildGeoProvider(countryDB, asnDB string) (prox.GeoProvider, error) {
+	var providers prox.MultiProvider
+	for _, path := range []string{countryDB, asnDB} {
+		if path == "" {
+			continue
+		}
+		provider, err := prox.NewMMDBProvider(path)
+		if err != nil {
+			return nil, fmt.Errorf("loading GeoIP database %s: %w", path, err)
+		}
+		providers = append(providers, provider)
+	}
+	if len(providers) == 0 {
+		return nil, nil
+	}
+	return providers, nil
+}
+
+// matchesFilters reports whether result satisfies countries (GeoIP country
+// codes, any match, case-insensitive) and anonymity (exact match), when
+// those filters are non-empty. A failed check never matches once any
+// filter is active, since it has neither a country nor an anonymity level
+// to match against.
+func matchesFilters(result prox.Result, countries []string, anonymity prox.AnonymityLevel) bool {
+	if len(countries) == 0 && anonymity == "" {
+		return true
+	}
+	if !result.IsWorking {
+		return false
+	}
+	if anonymity != "" && result.Anonymity != anonymity {
+		return false
+	}
+	if len(countries) > 0 && !containsCountry(countries, result.Country) {
+		return false
+	}
+	return true
+}
+
+// splitAndTrim splits s on commas, trims whitespace from each part, and
+// drops empty parts. An empty or blank s returns nil.
+func splitAndTrim(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func containsCountry(countries []string, country string) bool {
+	for _, c := range countries {
+		if strings.EqualFold(c, country) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStdinAvailable checks if there's data available on stdin
+func isStdinAvailable() bool {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return (stat.Mode() & os.ModeCharDevice) == 0
+}
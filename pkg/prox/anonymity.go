@@ -0,0 +1,58 @@
+package prox
+
+import (
+	"net/http"
+	"strings"
+)
+
+// AnonymityLevel classifies how much a proxy reveals about the client
+// making requests through it.
+type AnonymityLevel string
+
+const (
+	// AnonymityTransparent proxies forward the client's real IP to the
+	// destination, usually via Via/X-Forwarded-For/Forwarded/X-Real-IP.
+	AnonymityTransparent AnonymityLevel = "transparent"
+	// AnonymityAnonymous proxies identify themselves as a proxy but do
+	// not leak the client's real IP.
+	AnonymityAnonymous AnonymityLevel = "anonymous"
+	// AnonymityElite proxies add none of the usual proxy-revealing
+	// headers at all.
+	AnonymityElite AnonymityLevel = "elite"
+)
+
+// anonymityHeaders are the request headers a destination server would see
+// echoed back if a proxy (or the client itself) added them.
+var anonymityHeaders = []string{"Via", "X-Forwarded-For", "Forwarded", "X-Real-Ip"}
+
+// classifyAnonymity determines a proxy's anonymity level from the headers
+// a check endpoint reports having received, and the client's own real
+// public IP (fetched directly, without going through any proxy).
+func classifyAnonymity(headers http.Header, realIP string) AnonymityLevel {
+	switch {
+	case realIP != "" && headersContainValue(headers, realIP):
+		return AnonymityTransparent
+	case hasAnyHeader(headers, anonymityHeaders):
+		return AnonymityAnonymous
+	default:
+		return AnonymityElite
+	}
+}
+
+func hasAnyHeader(headers http.Header, names []string) bool {
+	for _, name := range names {
+		if headers.Get(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func headersContainValue(headers http.Header, value string) bool {
+	for _, name := range anonymityHeaders {
+		if v := headers.Get(name); v != "" && strings.Contains(v, value) {
+			return true
+		}
+	}
+	return false
+}
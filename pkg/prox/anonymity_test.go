@@ -0,0 +1,33 @@
+package prox
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClassifyAnonymityTransparent(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-Forwarded-For", "203.0.113.5")
+
+	if level := classifyAnonymity(headers, "203.0.113.5"); level != AnonymityTransparent {
+		t.Fatalf("got %q, want %q", level, AnonymityTransparent)
+	}
+}
+
+func TestClassifyAnonymityAnonymous(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("Via", "1.1 some-proxy")
+
+	if level := classifyAnonymity(headers, "203.0.113.5"); level != AnonymityAnonymous {
+		t.Fatalf("got %q, want %q", level, AnonymityAnonymous)
+	}
+}
+
+func TestClassifyAnonymityElite(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("User-Agent", "gep/1.0")
+
+	if level := classifyAnonymity(headers, "203.0.113.5"); level != AnonymityElite {
+		t.Fatalf("got %q, want %q", level, AnonymityElite)
+	}
+}
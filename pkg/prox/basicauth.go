@@ -0,0 +1,21 @@
+package prox
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// parseProxyBasicAuth decodes a "Proxy-Authorization: Basic ..." header
+// value into a username and password.
+func parseProxyBasicAuth(header string) (user, pass string, ok bool) {
+	const prefix = "Basic "
+	if !strings.HasPrefix(header, prefix) {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(header[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	user, pass, ok = strings.Cut(string(decoded), ":")
+	return user, pass, ok
+}
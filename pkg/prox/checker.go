@@ -0,0 +1,328 @@
+package prox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Checker validates a single proxy and reports what it found. Implementations
+// are free to perform any kind of check (HTTP GET, TLS handshake, DNS
+// resolution, ...) as long as they can tell whether the proxy is usable.
+type Checker interface {
+	// Check dials proxyURL and reports whether it is working.
+	Check(ctx context.Context, proxyURL string) Result
+}
+
+// HTTPChecker is the default Checker: it issues an HTTP GET through the
+// proxy against an endpoint that echoes back the caller's IP, and considers
+// the proxy working if the response is a bare IP address.
+type HTTPChecker struct {
+	// URL is the endpoint to fetch through the proxy. Defaults to
+	// "http://ifconfig.co/ip" when empty. Ignored when URLs is set.
+	URL string
+	// URLs, when non-empty, are check endpoints queried round-robin
+	// across checks, instead of always hitting URL, so no single
+	// endpoint receives all the traffic from a large test run.
+	URLs []string
+	// urlIdx is the round-robin cursor into URLs, advanced atomically
+	// since a Checker is shared across concurrent workers.
+	urlIdx uint64
+	// UserAgent is sent on the check request. Defaults to "gep/1.0" when empty.
+	UserAgent string
+	// Timeout bounds the whole check, including connect and TLS handshake.
+	// Defaults to 15s when zero.
+	Timeout time.Duration
+
+	// RealIP is the caller's own public IP, fetched once with a direct
+	// (non-proxied) connection before testing begins. When set, it is
+	// used to classify a working proxy as AnonymityTransparent. See
+	// FetchRealIP.
+	RealIP string
+	// HeaderURL is an endpoint that echoes back the request headers it
+	// received, as a JSON object under a top-level "headers" key (the
+	// format httpbin.org/headers and similar services use). Defaults to
+	// "http://httpbin.org/headers" when empty. Used together with RealIP
+	// to classify anonymity; left unset, Anonymity is never populated.
+	HeaderURL string
+	// GeoProvider, when set, enriches each working proxy's Result with
+	// Country/ASN/Org looked up from its IP.
+	GeoProvider GeoProvider
+	// RateLimiter, when set, is consulted before every outbound request
+	// this Checker makes (check and header requests alike), keyed by
+	// the request's target host, so a large test run never exceeds a
+	// configured QPS against any one check endpoint.
+	RateLimiter *HostRateLimiter
+}
+
+// FetchRealIP fetches the caller's own public IP with a direct (no proxy)
+// request to checkURL, the same endpoint Checkers use to see what IP a
+// proxy exposes. An empty checkURL falls back to the default
+// "http://ifconfig.co/ip". Call this once before running a batch of
+// checks and assign the result to HTTPChecker.RealIP.
+func FetchRealIP(ctx context.Context, checkURL string) (string, error) {
+	if checkURL == "" {
+		checkURL = "http://ifconfig.co/ip"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", checkURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("fetch real IP: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch real IP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("fetch real IP: %w", err)
+	}
+
+	ip := strings.TrimSpace(string(body))
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("fetch real IP: invalid IP response %q", ip)
+	}
+	return ip, nil
+}
+
+// NewHTTPChecker returns an HTTPChecker that fetches checkURL through each
+// proxy. An empty checkURL falls back to the default "http://ifconfig.co/ip".
+func NewHTTPChecker(checkURL string) *HTTPChecker {
+	return &HTTPChecker{URL: checkURL}
+}
+
+func (c *HTTPChecker) url() string {
+	if len(c.URLs) > 0 {
+		i := atomic.AddUint64(&c.urlIdx, 1) - 1
+		return c.URLs[i%uint64(len(c.URLs))]
+	}
+	if c.URL != "" {
+		return c.URL
+	}
+	return "http://ifconfig.co/ip"
+}
+
+func (c *HTTPChecker) userAgent() string {
+	if c.UserAgent != "" {
+		return c.UserAgent
+	}
+	return "gep/1.0"
+}
+
+func (c *HTTPChecker) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return 15 * time.Second
+}
+
+func (c *HTTPChecker) headerURL() string {
+	if c.HeaderURL != "" {
+		return c.HeaderURL
+	}
+	return "http://httpbin.org/headers"
+}
+
+// probeSchemes are tried in order, against the same host:port, when a
+// proxy is given without an explicit scheme. ssh is never probed: it
+// always requires an explicit scheme and credentials.
+var probeSchemes = []string{"http", "socks5", "socks4a"}
+
+// Check implements Checker. proxyURL may omit its scheme (a bare
+// "host:port"), in which case Check probes probeSchemes in order and
+// returns the first one that works, recording the detected scheme on
+// the returned Result.
+func (c *HTTPChecker) Check(ctx context.Context, proxyURL string) Result {
+	start := time.Now()
+
+	if !strings.Contains(proxyURL, "://") {
+		var last Result
+		for _, scheme := range probeSchemes {
+			parsedProxy, err := url.Parse(scheme + "://" + proxyURL)
+			if err != nil {
+				continue
+			}
+			last = c.check(ctx, proxyURL, parsedProxy, start)
+			if last.IsWorking {
+				return last
+			}
+		}
+		if last.Proxy == "" {
+			last = Result{Proxy: proxyURL, Error: "could not detect proxy scheme", Duration: time.Since(start)}
+		}
+		return last
+	}
+
+	parsedProxy, err := url.Parse(proxyURL)
+	if err != nil {
+		return Result{Proxy: proxyURL, Error: fmt.Sprintf("invalid proxy URL: %v", err), Duration: time.Since(start)}
+	}
+	return c.check(ctx, proxyURL, parsedProxy, start)
+}
+
+// check runs a single scheme-specific attempt against parsedProxy.
+// displayProxy is the value recorded on the Result, which for probed
+// proxies is the original schemeless "host:port".
+func (c *HTTPChecker) check(ctx context.Context, displayProxy string, parsedProxy *url.URL, start time.Time) Result {
+	result := Result{Proxy: displayProxy, Scheme: parsedProxy.Scheme}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	transport, err := c.transportFor(parsedProxy)
+	if err != nil {
+		result.Error = err.Error()
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   c.timeout(),
+	}
+
+	checkURL := c.url()
+	req, err := http.NewRequestWithContext(ctx, "GET", checkURL, nil)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		result.Duration = time.Since(start)
+		return result
+	}
+	req.Header.Set("User-Agent", c.userAgent())
+
+	if err := c.RateLimiter.Wait(ctx, requestHost(checkURL)); err != nil {
+		result.Error = fmt.Sprintf("rate limit wait: %v", err)
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Error = fmt.Sprintf("request failed: %v", err)
+		result.Duration = time.Since(start)
+		return result
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		result.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read response: %v", err)
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	ip := strings.TrimSpace(string(body))
+	if net.ParseIP(ip) == nil {
+		result.Error = "invalid IP response"
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	result.IsWorking = true
+	result.IP = ip
+	result.Duration = time.Since(start)
+
+	if headers, err := c.fetchHeaders(ctx, client); err == nil {
+		result.Anonymity = classifyAnonymity(headers, c.RealIP)
+	}
+	if c.GeoProvider != nil {
+		if geo, err := c.GeoProvider.Lookup(net.ParseIP(ip)); err == nil {
+			result.Country, result.ASN, result.Org = geo.Country, geo.ASN, geo.Org
+		}
+	}
+
+	return result
+}
+
+// fetchHeaders requests c.headerURL() through client and parses the JSON
+// object it returns into an http.Header, so classifyAnonymity can inspect
+// it the same way it would a proxy-forwarded request's headers.
+func (c *HTTPChecker) fetchHeaders(ctx context.Context, client *http.Client) (http.Header, error) {
+	headerURL := c.headerURL()
+	req, err := http.NewRequestWithContext(ctx, "GET", headerURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent())
+
+	if err := c.RateLimiter.Wait(ctx, requestHost(headerURL)); err != nil {
+		return nil, fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("header check: HTTP %d", resp.StatusCode)
+	}
+
+	var echoed struct {
+		Headers map[string]string `json:"headers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&echoed); err != nil {
+		return nil, fmt.Errorf("header check: %w", err)
+	}
+
+	headers := make(http.Header, len(echoed.Headers))
+	for name, value := range echoed.Headers {
+		headers.Set(name, value)
+	}
+	return headers, nil
+}
+
+// transportFor builds an http.Transport that routes through parsedProxy,
+// using dialerForProxy for schemes net/http cannot proxy through natively.
+func (c *HTTPChecker) transportFor(parsedProxy *url.URL) (*http.Transport, error) {
+	dial, err := dialerForProxy(parsedProxy, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if dial == nil {
+		transport.Proxy = http.ProxyURL(parsedProxy)
+		transport.DialContext = (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	} else {
+		transport.DialContext = dial
+	}
+
+	return transport, nil
+}
+
+// requestHost extracts the host:port a HostRateLimiter should key on from
+// rawURL. An unparseable rawURL is used as-is, so the limiter still has
+// some key to rate-limit against instead of silently not limiting.
+func requestHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+	return parsed.Host
+}
@@ -0,0 +1,46 @@
+package prox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// DBLoaderScheme is the URL scheme that selects DBLoader as a proxy
+// source, e.g. "db://working?min_uptime=0.8".
+const DBLoaderScheme = "db"
+
+// DBLoader loads proxies that a Store has historically found reliable,
+// so a new run can be seeded with proxies already known to work. The URL
+// is of the form "db://working?min_uptime=0.8": the host selects which
+// query to run (only "working" is defined so far) and min_uptime sets
+// the minimum fraction of past checks that must have succeeded.
+type DBLoader struct {
+	Store Store
+	URL   string
+}
+
+// Load implements Loader.
+func (l DBLoader) Load(ctx context.Context) ([]string, error) {
+	parsed, err := url.Parse(l.URL)
+	if err != nil {
+		return nil, fmt.Errorf("dbloader: parsing %q: %w", l.URL, err)
+	}
+	if parsed.Scheme != DBLoaderScheme {
+		return nil, fmt.Errorf("dbloader: %q does not use the %q scheme", l.URL, DBLoaderScheme)
+	}
+	if parsed.Host != "working" {
+		return nil, fmt.Errorf("dbloader: unknown query %q (only \"working\" is supported)", parsed.Host)
+	}
+
+	minUptime := 0.8
+	if raw := parsed.Query().Get("min_uptime"); raw != "" {
+		minUptime, err = strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("dbloader: invalid min_uptime %q: %w", raw, err)
+		}
+	}
+
+	return l.Store.ReliableProxies(ctx, minUptime)
+}
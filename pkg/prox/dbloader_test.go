@@ -0,0 +1,40 @@
+package prox
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDBLoaderLoadsReliableProxies(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	mustRecord(t, store, Result{Proxy: "good:1", IsWorking: true}, now)
+	mustRecord(t, store, Result{Proxy: "good:1", IsWorking: true}, now.Add(-time.Minute))
+	mustRecord(t, store, Result{Proxy: "bad:1", IsWorking: false}, now)
+
+	loader := DBLoader{Store: store, URL: "db://working?min_uptime=0.8"}
+	proxies, err := loader.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(proxies) != 1 || proxies[0] != "good:1" {
+		t.Fatalf("Load = %v, want only good:1", proxies)
+	}
+}
+
+func TestDBLoaderRejectsWrongScheme(t *testing.T) {
+	loader := DBLoader{URL: "file://working"}
+	if _, err := loader.Load(context.Background()); err == nil {
+		t.Fatal("Load with a non-db:// URL returned nil error, want an error")
+	}
+}
+
+func TestDBLoaderRejectsUnknownQuery(t *testing.T) {
+	loader := DBLoader{URL: "db://unknown"}
+	if _, err := loader.Load(context.Background()); err == nil {
+		t.Fatal("Load with an unknown query returned nil error, want an error")
+	}
+}
@@ -0,0 +1,57 @@
+package prox
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// contextDialFunc dials addr through a specific proxy scheme.
+type contextDialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// dialerForProxy returns a DialContext function that tunnels connections
+// through proxyURL, or (nil, nil) for schemes ("http", "https") that
+// net/http's Transport already knows how to proxy through on its own via
+// http.ProxyURL, in which case the caller should not override DialContext.
+func dialerForProxy(proxyURL *url.URL, timeout time.Duration) (contextDialFunc, error) {
+	switch proxyURL.Scheme {
+	case "http", "https":
+		return nil, nil
+	case "socks5":
+		return socks5Dialer(proxyURL, timeout)
+	case "socks4", "socks4a":
+		return socks4Dialer(proxyURL, timeout)
+	case "ssh":
+		return sshDialer(proxyURL, timeout)
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+}
+
+func socks5Dialer(proxyURL *url.URL, timeout time.Duration) (contextDialFunc, error) {
+	var auth *proxy.Auth
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		auth = &proxy.Auth{User: proxyURL.User.Username(), Password: password}
+	}
+
+	forward := &net.Dialer{Timeout: timeout}
+	dialer, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, forward)
+	if err != nil {
+		return nil, fmt.Errorf("socks5 dialer: %w", err)
+	}
+
+	ctxDialer, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		// proxy.SOCKS5 always returns a ContextDialer in practice; this is
+		// only a defensive fallback.
+		return func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.Dial(network, addr)
+		}, nil
+	}
+	return ctxDialer.DialContext, nil
+}
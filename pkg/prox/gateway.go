@@ -0,0 +1,356 @@
+package prox
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Gateway runs gep as a local forward proxy that routes every request
+// through an upstream selected from a Pool.
+type Gateway struct {
+	// Pool supplies the upstream proxies to route through.
+	Pool *Pool
+	// Auth, when non-nil, requires clients to authenticate with HTTP
+	// Basic-Auth verified against it.
+	Auth *Htpasswd
+	// CA, when non-nil, enables MITM of CONNECT tunnels: the Gateway
+	// terminates TLS using a certificate minted for the target host,
+	// so it can classify the proxied response.
+	CA *CertAuthority
+	// DialTimeout bounds connecting to the upstream proxy. Defaults to
+	// 10s when zero.
+	DialTimeout time.Duration
+
+	transportsMu sync.Mutex
+	transports   map[string]*http.Transport
+}
+
+// NewGateway creates a Gateway that selects upstreams from pool.
+func NewGateway(pool *Pool) *Gateway {
+	return &Gateway{Pool: pool, transports: make(map[string]*http.Transport)}
+}
+
+func (g *Gateway) dialTimeout() time.Duration {
+	if g.DialTimeout > 0 {
+		return g.DialTimeout
+	}
+	return 10 * time.Second
+}
+
+// Dial selects a healthy upstream and returns a connection to addr dialed
+// through it, for use by non-HTTP listeners such as SOCKS5Server.
+func (g *Gateway) Dial(network, addr string) (net.Conn, error) {
+	return g.dialUpstream("", addr)
+}
+
+// dialUpstream selects a healthy upstream (sticky on clientAddr) and
+// returns a connection to target dialed through it.
+func (g *Gateway) dialUpstream(clientAddr, target string) (net.Conn, error) {
+	upstream, err := g.Pool.Select(clientAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedProxy, err := url.Parse(upstream)
+	if err != nil {
+		return nil, fmt.Errorf("gateway: invalid upstream %q: %w", upstream, err)
+	}
+
+	dial, err := dialerForProxy(parsedProxy, g.dialTimeout())
+	if err != nil {
+		return nil, err
+	}
+	if dial == nil {
+		// http(s) upstream: CONNECT through it ourselves.
+		return connectThroughHTTPProxy(parsedProxy, target, g.dialTimeout())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.dialTimeout())
+	defer cancel()
+	return dial(ctx, "tcp", target)
+}
+
+// connectThroughHTTPProxy issues a CONNECT request to an http(s) upstream
+// proxy and returns the resulting tunnel.
+func connectThroughHTTPProxy(proxyURL *url.URL, target string, timeout time.Duration) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", proxyURL.Host, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodConnect, "http://"+target, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	req.Host = target
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		req.SetBasicAuth(proxyURL.User.Username(), password)
+	}
+	// Use Write, not WriteProxy: for CONNECT requests WriteProxy prefers
+	// the absolute-URI form whenever the URL carries a scheme, which
+	// produces "CONNECT http://host:port/ HTTP/1.1" instead of the
+	// authority-form "CONNECT host:port HTTP/1.1" proxies expect.
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		conn.Close()
+		return nil, fmt.Errorf("gateway: upstream CONNECT failed: %s: %s", resp.Status, body)
+	}
+	return conn, nil
+}
+
+// ServeHTTP implements http.Handler, forwarding CONNECT and plain HTTP
+// requests through the pool.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if g.Auth != nil && !g.checkAuth(r) {
+		w.Header().Set("Proxy-Authenticate", `Basic realm="gep"`)
+		http.Error(w, "Proxy Authentication Required", http.StatusProxyAuthRequired)
+		return
+	}
+
+	if r.Method == http.MethodConnect {
+		g.handleConnect(w, r)
+		return
+	}
+	g.handlePlainHTTP(w, r)
+}
+
+func (g *Gateway) checkAuth(r *http.Request) bool {
+	user, pass, ok := parseProxyBasicAuth(r.Header.Get("Proxy-Authorization"))
+	if !ok {
+		return false
+	}
+	return g.Auth.Verify(user, pass)
+}
+
+func (g *Gateway) handlePlainHTTP(w http.ResponseWriter, r *http.Request) {
+	upstream, err := g.Pool.Select(r.RemoteAddr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	parsedProxy, err := url.Parse(upstream)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	transport, err := g.transportFor(upstream, parsedProxy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	outReq := r.Clone(r.Context())
+	outReq.RequestURI = ""
+	removeHopByHopHeaders(outReq.Header)
+
+	resp, err := transport.RoundTrip(outReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for k, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// transportFor returns the shared *http.Transport for upstream (keyed by
+// its literal URL, as selected from the Pool), building and caching one
+// on first use via dialerForProxy. Reusing one transport per upstream
+// across requests — instead of allocating a fresh one per request — lets
+// net/http pool and reuse its idle connections to that upstream, and
+// routes schemes (socks4, socks4a, ssh) that http.ProxyURL can't handle.
+func (g *Gateway) transportFor(upstream string, parsedProxy *url.URL) (*http.Transport, error) {
+	g.transportsMu.Lock()
+	if transport, ok := g.transports[upstream]; ok {
+		g.transportsMu.Unlock()
+		return transport, nil
+	}
+	g.transportsMu.Unlock()
+
+	dial, err := dialerForProxy(parsedProxy, g.dialTimeout())
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		TLSHandshakeTimeout:   g.dialTimeout(),
+		ResponseHeaderTimeout: g.dialTimeout(),
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if dial == nil {
+		transport.Proxy = http.ProxyURL(parsedProxy)
+		transport.DialContext = (&net.Dialer{
+			Timeout:   g.dialTimeout(),
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	} else {
+		transport.DialContext = dial
+	}
+
+	g.transportsMu.Lock()
+	defer g.transportsMu.Unlock()
+	if existing, ok := g.transports[upstream]; ok {
+		// Another goroutine built one first; keep theirs so every
+		// request to this upstream shares a single connection pool.
+		transport.CloseIdleConnections()
+		return existing, nil
+	}
+	g.transports[upstream] = transport
+	return transport, nil
+}
+
+// PruneTransports closes and forgets the cached transport for any
+// upstream not in keep, so an upstream list reload doesn't leak a
+// transport (and its idle connections) for every upstream ever seen,
+// forever.
+func (g *Gateway) PruneTransports(keep []string) {
+	keepSet := make(map[string]bool, len(keep))
+	for _, upstream := range keep {
+		keepSet[upstream] = true
+	}
+
+	g.transportsMu.Lock()
+	defer g.transportsMu.Unlock()
+	for upstream, transport := range g.transports {
+		if !keepSet[upstream] {
+			transport.CloseIdleConnections()
+			delete(g.transports, upstream)
+		}
+	}
+}
+
+// hopByHopHeaders are the headers RFC 7230 §6.1 scopes to a single
+// connection rather than the end-to-end request; they must not be
+// forwarded to the upstream proxy. This matters most for
+// Proxy-Authorization, which authenticates the client to this Gateway
+// and must not leak to the next hop.
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// removeHopByHopHeaders deletes hopByHopHeaders from h, along with any
+// additional header named in a "Connection" header, per RFC 7230 §6.1.
+func removeHopByHopHeaders(h http.Header) {
+	for _, name := range strings.Split(h.Get("Connection"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			h.Del(name)
+		}
+	}
+	for _, name := range hopByHopHeaders {
+		h.Del(name)
+	}
+}
+
+func (g *Gateway) handleConnect(w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer clientConn.Close()
+
+	upstreamConn, err := g.dialUpstream(r.RemoteAddr, r.Host)
+	if err != nil {
+		clientConn.Write([]byte("HTTP/1.1 502 Bad Gateway\r\n\r\n"))
+		return
+	}
+	defer upstreamConn.Close()
+
+	clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+
+	if g.CA == nil {
+		pipeConns(clientConn, upstreamConn)
+		return
+	}
+	g.mitm(clientConn, upstreamConn, r.Host)
+}
+
+// mitm terminates TLS with the client using a certificate minted for host,
+// then relays each decrypted request to upstreamConn over its own TLS
+// session, so the Gateway can observe plaintext traffic passing through.
+func (g *Gateway) mitm(clientConn, upstreamConn net.Conn, host string) {
+	hostname, _, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+	}
+
+	leaf, err := g.CA.LeafFor(hostname)
+	if err != nil {
+		return
+	}
+
+	tlsClientConn := tls.Server(clientConn, &tls.Config{Certificates: []tls.Certificate{*leaf}})
+	defer tlsClientConn.Close()
+	if err := tlsClientConn.Handshake(); err != nil {
+		return
+	}
+
+	tlsUpstreamConn := tls.Client(upstreamConn, &tls.Config{ServerName: hostname})
+	defer tlsUpstreamConn.Close()
+	if err := tlsUpstreamConn.Handshake(); err != nil {
+		return
+	}
+
+	clientReader := bufio.NewReader(tlsClientConn)
+	for {
+		req, err := http.ReadRequest(clientReader)
+		if err != nil {
+			return
+		}
+		req.URL.Scheme = "https"
+		req.URL.Host = host
+
+		if err := req.Write(tlsUpstreamConn); err != nil {
+			return
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(tlsUpstreamConn), req)
+		if err != nil {
+			return
+		}
+		if err := resp.Write(tlsClientConn); err != nil {
+			resp.Body.Close()
+			return
+		}
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,32 @@
+package prox
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestGatewayPruneTransportsDropsOnlyUnlisted(t *testing.T) {
+	g := NewGateway(NewPool(StrategyRoundRobin, 0))
+
+	for _, upstream := range []string{"http://a:1", "http://b:1", "http://c:1"} {
+		parsed, err := url.Parse(upstream)
+		if err != nil {
+			t.Fatalf("url.Parse(%q): %v", upstream, err)
+		}
+		if _, err := g.transportFor(upstream, parsed); err != nil {
+			t.Fatalf("transportFor(%q): %v", upstream, err)
+		}
+	}
+
+	g.PruneTransports([]string{"http://a:1", "http://c:1"})
+
+	if len(g.transports) != 2 {
+		t.Fatalf("transports = %v, want exactly the kept upstreams", g.transports)
+	}
+	if _, ok := g.transports["http://b:1"]; ok {
+		t.Fatal("PruneTransports left a transport for an upstream not in keep")
+	}
+	if _, ok := g.transports["http://a:1"]; !ok {
+		t.Fatal("PruneTransports dropped a transport for an upstream in keep")
+	}
+}
@@ -0,0 +1,248 @@
+package prox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+)
+
+// GeoInfo holds the geolocation facts a GeoProvider was able to find for
+// an IP address. Fields are left empty when the provider's database did
+// not have an answer for them.
+type GeoInfo struct {
+	Country string
+	ASN     string
+	Org     string
+}
+
+// GeoProvider enriches a working proxy's IP with geolocation data. It is
+// intentionally narrow so callers can plug in anything from a MaxMind
+// database to an HTTP lookup service.
+type GeoProvider interface {
+	Lookup(ip net.IP) (GeoInfo, error)
+}
+
+// MultiProvider queries a list of GeoProviders in order and merges their
+// answers, keeping the first non-empty value seen for each field. This is
+// how gep combines, say, a GeoLite2-Country database with a GeoLite2-ASN
+// one: neither alone has both Country and ASN/Org.
+type MultiProvider []GeoProvider
+
+// Lookup implements GeoProvider.
+func (m MultiProvider) Lookup(ip net.IP) (GeoInfo, error) {
+	var merged GeoInfo
+	for _, provider := range m {
+		info, err := provider.Lookup(ip)
+		if err != nil {
+			continue
+		}
+		if merged.Country == "" {
+			merged.Country = info.Country
+		}
+		if merged.ASN == "" {
+			merged.ASN = info.ASN
+		}
+		if merged.Org == "" {
+			merged.Org = info.Org
+		}
+	}
+	return merged, nil
+}
+
+// metadataMarker precedes the metadata section near the end of every
+// MaxMind DB file.
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// maxMetadataSearch bounds how far from the end of the file we look for
+// metadataMarker, matching the MaxMind DB spec.
+const maxMetadataSearch = 128 * 1024
+
+// dataSectionSeparatorSize is the gap between the search tree and the
+// data section in an MaxMind DB file.
+const dataSectionSeparatorSize = 16
+
+// MMDBProvider is a GeoProvider backed by a MaxMind DB (.mmdb) file, such
+// as GeoLite2-Country or GeoLite2-ASN. It implements just enough of the
+// binary format (https://maxmind.github.io/MaxMind-DB/) to walk the
+// search tree for an IP and decode the data record it points at, so gep
+// does not need a dependency on MaxMind's own library.
+type MMDBProvider struct {
+	data       []byte
+	searchTree []byte
+	nodeCount  int
+	recordSize int
+	ipVersion  int
+}
+
+// NewMMDBProvider opens and parses the MaxMind DB file at path.
+func NewMMDBProvider(path string) (*MMDBProvider, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: %w", err)
+	}
+
+	metaStart, err := findMetadataStart(raw)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: %s: %w", path, err)
+	}
+
+	d := &decoder{data: raw}
+	meta, _, err := d.decode(metaStart)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: %s: invalid metadata: %w", path, err)
+	}
+	metaMap, ok := meta.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("geoip: %s: metadata is not a map", path)
+	}
+
+	nodeCount, _ := toInt(metaMap["node_count"])
+	recordSize, _ := toInt(metaMap["record_size"])
+	ipVersion, _ := toInt(metaMap["ip_version"])
+	if nodeCount == 0 || recordSize == 0 {
+		return nil, fmt.Errorf("geoip: %s: missing node_count/record_size in metadata", path)
+	}
+
+	searchTreeSize := nodeCount * recordSize * 2 / 8
+	if searchTreeSize > len(raw) {
+		return nil, fmt.Errorf("geoip: %s: search tree larger than file", path)
+	}
+
+	return &MMDBProvider{
+		data:       raw,
+		searchTree: raw[:searchTreeSize],
+		nodeCount:  nodeCount,
+		recordSize: recordSize,
+		ipVersion:  ipVersion,
+	}, nil
+}
+
+// findMetadataStart scans the last maxMetadataSearch bytes of raw for
+// metadataMarker and returns the offset of the metadata value that
+// follows it.
+func findMetadataStart(raw []byte) (int, error) {
+	searchFrom := 0
+	if len(raw) > maxMetadataSearch {
+		searchFrom = len(raw) - maxMetadataSearch
+	}
+
+	idx := bytes.LastIndex(raw[searchFrom:], metadataMarker)
+	if idx < 0 {
+		return 0, fmt.Errorf("not a MaxMind DB file (metadata marker not found)")
+	}
+	return searchFrom + idx + len(metadataMarker), nil
+}
+
+// Lookup implements GeoProvider.
+func (p *MMDBProvider) Lookup(ip net.IP) (GeoInfo, error) {
+	bits := ip.To4()
+	if p.ipVersion == 6 || bits == nil {
+		bits = ip.To16()
+	}
+	if bits == nil {
+		return GeoInfo{}, fmt.Errorf("geoip: invalid IP %v", ip)
+	}
+
+	node := 0
+	for _, b := range bits {
+		for i := 7; i >= 0; i-- {
+			if node >= p.nodeCount {
+				break
+			}
+			bit := (b >> uint(i)) & 1
+			left, right := p.readNode(node)
+			if bit == 0 {
+				node = left
+			} else {
+				node = right
+			}
+		}
+	}
+
+	if node == p.nodeCount {
+		return GeoInfo{}, nil // no record for this IP
+	}
+	if node < p.nodeCount {
+		return GeoInfo{}, fmt.Errorf("geoip: search tree did not terminate in a data pointer")
+	}
+
+	offset := node - p.nodeCount - dataSectionSeparatorSize
+	d := &decoder{data: p.data}
+	value, _, err := d.decode(len(p.searchTree) + dataSectionSeparatorSize + offset)
+	if err != nil {
+		return GeoInfo{}, fmt.Errorf("geoip: decode record: %w", err)
+	}
+
+	record, ok := value.(map[string]any)
+	if !ok {
+		return GeoInfo{}, fmt.Errorf("geoip: record is not a map")
+	}
+	return geoInfoFromRecord(record), nil
+}
+
+// readNode returns the left and right record values of search tree node
+// n, each either another node index or (index >= p.nodeCount) a pointer
+// into the data section.
+func (p *MMDBProvider) readNode(n int) (left, right int) {
+	recordBytes := p.recordSize * 2 / 8
+	base := n * recordBytes
+	rec := p.searchTree[base : base+recordBytes]
+
+	switch p.recordSize {
+	case 24:
+		left = int(rec[0])<<16 | int(rec[1])<<8 | int(rec[2])
+		right = int(rec[3])<<16 | int(rec[4])<<8 | int(rec[5])
+	case 28:
+		left = int(rec[0])<<16 | int(rec[1])<<8 | int(rec[2]) | int(rec[3]&0xF0)<<20
+		right = int(rec[4])<<16 | int(rec[5])<<8 | int(rec[6]) | int(rec[3]&0x0F)<<24
+	case 32:
+		left = int(binary.BigEndian.Uint32(rec[0:4]))
+		right = int(binary.BigEndian.Uint32(rec[4:8]))
+	}
+	return left, right
+}
+
+// geoInfoFromRecord pulls the fields gep cares about out of a decoded
+// MaxMind data record. The same extraction handles GeoLite2-Country
+// records (a "country" map), GeoLite2-ASN records
+// ("autonomous_system_number"/"autonomous_system_organization") and
+// GeoIP2-ISP records ("isp"/"organization"), since callers may point
+// MMDBProvider at any one of them.
+func geoInfoFromRecord(record map[string]any) GeoInfo {
+	var info GeoInfo
+
+	if country, ok := record["country"].(map[string]any); ok {
+		if iso, ok := country["iso_code"].(string); ok {
+			info.Country = iso
+		}
+	}
+
+	if asn, ok := toInt(record["autonomous_system_number"]); ok {
+		info.ASN = fmt.Sprintf("AS%d", asn)
+	}
+	if org, ok := record["autonomous_system_organization"].(string); ok {
+		info.Org = org
+	} else if isp, ok := record["isp"].(string); ok {
+		info.Org = isp
+	} else if org, ok := record["organization"].(string); ok {
+		info.Org = org
+	}
+
+	return info
+}
+
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case uint16:
+		return int(n), true
+	case uint32:
+		return int(n), true
+	case uint64:
+		return int(n), true
+	case int32:
+		return int(n), true
+	}
+	return 0, false
+}
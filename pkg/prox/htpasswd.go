@@ -0,0 +1,54 @@
+package prox
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Htpasswd is an in-memory view of an Apache htpasswd file, supporting
+// bcrypt ($2a$/$2b$/$2y$) entries as produced by `htpasswd -B`.
+type Htpasswd struct {
+	hashes map[string]string
+}
+
+// LoadHtpasswd parses an htpasswd file at path.
+func LoadHtpasswd(path string) (*Htpasswd, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hashes := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("htpasswd: malformed line %q", line)
+		}
+		hashes[user] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Htpasswd{hashes: hashes}, nil
+}
+
+// Verify reports whether password is correct for user. It returns false,
+// without error, for unknown users or non-bcrypt entries.
+func (h *Htpasswd) Verify(user, password string) bool {
+	hash, ok := h.hashes[user]
+	if !ok || !strings.HasPrefix(hash, "$2") {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,59 @@
+package prox
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+)
+
+// Loader produces a list of proxy URLs to test, from whatever source it
+// wraps (a file, a reader, a database query, ...).
+type Loader interface {
+	Load(ctx context.Context) ([]string, error)
+}
+
+// parseProxyLines splits reader into non-empty, non-comment lines. A line
+// already carrying a scheme (e.g. "socks5://1.2.3.4:1080") is kept as-is;
+// a bare "host:port" is left schemeless so Checker implementations such as
+// HTTPChecker can auto-detect the right scheme by probing.
+func parseProxyLines(reader io.Reader) ([]string, error) {
+	var proxies []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		proxies = append(proxies, line)
+	}
+
+	return proxies, scanner.Err()
+}
+
+// ReaderLoader loads proxies from an arbitrary io.Reader, one per line.
+type ReaderLoader struct {
+	Reader io.Reader
+}
+
+// Load implements Loader.
+func (l ReaderLoader) Load(ctx context.Context) ([]string, error) {
+	return parseProxyLines(l.Reader)
+}
+
+// FileLoader loads proxies from a text file, one per line.
+type FileLoader struct {
+	Path string
+}
+
+// Load implements Loader.
+func (l FileLoader) Load(ctx context.Context) ([]string, error) {
+	file, err := os.Open(l.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseProxyLines(file)
+}
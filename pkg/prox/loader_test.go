@@ -0,0 +1,29 @@
+package prox
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestReaderLoaderKeepsSchemesAsGiven(t *testing.T) {
+	input := "192.168.1.1:8080\n# comment\n\nhttps://10.0.0.1:443\nsocks5://10.0.0.2:1080\n"
+	proxies, err := ReaderLoader{Reader: strings.NewReader(input)}.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	want := []string{
+		"192.168.1.1:8080",
+		"https://10.0.0.1:443",
+		"socks5://10.0.0.2:1080",
+	}
+	if len(proxies) != len(want) {
+		t.Fatalf("got %d proxies, want %d: %v", len(proxies), len(want), proxies)
+	}
+	for i, p := range proxies {
+		if p != want[i] {
+			t.Errorf("proxy %d = %q, want %q", i, p, want[i])
+		}
+	}
+}
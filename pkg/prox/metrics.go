@@ -0,0 +1,125 @@
+package prox
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Metrics collects counters and a latency histogram for proxy checks and
+// serves them in the Prometheus text exposition format. gep has no
+// dependency on a Prometheus client library, so this implements just the
+// handful of metric shapes gep needs directly; see
+// https://prometheus.io/docs/instrumenting/exposition_formats/.
+type Metrics struct {
+	mu sync.Mutex
+
+	checksTotal map[checksKey]int64
+
+	latencyBuckets []float64
+	// latencyCounts[i] is the number of observations <= latencyBuckets[i],
+	// i.e. already cumulative, matching what the exposition format wants.
+	latencyCounts []int64
+	latencySum    float64
+	latencyCount  int64
+
+	working int64
+}
+
+type checksKey struct {
+	scheme string
+	result string
+}
+
+// defaultLatencyBuckets are second boundaries for gep_proxy_latency_seconds,
+// widened from Prometheus' own client-library defaults since proxy checks
+// routinely take longer than a typical HTTP handler.
+var defaultLatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 15, 30}
+
+// NewMetrics creates an empty Metrics collector.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		checksTotal:    make(map[checksKey]int64),
+		latencyBuckets: defaultLatencyBuckets,
+		latencyCounts:  make([]int64, len(defaultLatencyBuckets)),
+	}
+}
+
+// Observe records the outcome of one proxy check, for gep_proxy_checks_total
+// and gep_proxy_latency_seconds.
+func (m *Metrics) Observe(result Result) {
+	status := "fail"
+	if result.IsWorking {
+		status = "ok"
+	}
+	scheme := result.Scheme
+	if scheme == "" {
+		scheme = "unknown"
+	}
+	seconds := result.Duration.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.checksTotal[checksKey{scheme, status}]++
+
+	m.latencySum += seconds
+	m.latencyCount++
+	for i, bound := range m.latencyBuckets {
+		if seconds <= bound {
+			m.latencyCounts[i]++
+		}
+	}
+}
+
+// SetWorking records the current number of proxies known to be working,
+// for the gep_proxy_working gauge.
+func (m *Metrics) SetWorking(n int) {
+	m.mu.Lock()
+	m.working = int64(n)
+	m.mu.Unlock()
+}
+
+// ServeHTTP writes every collected metric in the Prometheus text
+// exposition format, so Metrics can be mounted directly at /metrics.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP gep_proxy_checks_total Total number of proxy checks performed.")
+	fmt.Fprintln(w, "# TYPE gep_proxy_checks_total counter")
+	keys := make([]checksKey, 0, len(m.checksTotal))
+	for k := range m.checksTotal {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].scheme != keys[j].scheme {
+			return keys[i].scheme < keys[j].scheme
+		}
+		return keys[i].result < keys[j].result
+	})
+	for _, k := range keys {
+		fmt.Fprintf(w, "gep_proxy_checks_total{scheme=%q,result=%q} %d\n", k.scheme, k.result, m.checksTotal[k])
+	}
+
+	fmt.Fprintln(w, "# HELP gep_proxy_latency_seconds Latency of a proxy check.")
+	fmt.Fprintln(w, "# TYPE gep_proxy_latency_seconds histogram")
+	for i, bound := range m.latencyBuckets {
+		fmt.Fprintf(w, "gep_proxy_latency_seconds_bucket{le=%q} %d\n", formatFloat(bound), m.latencyCounts[i])
+	}
+	fmt.Fprintf(w, "gep_proxy_latency_seconds_bucket{le=\"+Inf\"} %d\n", m.latencyCount)
+	fmt.Fprintf(w, "gep_proxy_latency_seconds_sum %s\n", formatFloat(m.latencySum))
+	fmt.Fprintf(w, "gep_proxy_latency_seconds_count %d\n", m.latencyCount)
+
+	fmt.Fprintln(w, "# HELP gep_proxy_working Number of proxies currently known to be working.")
+	fmt.Fprintln(w, "# TYPE gep_proxy_working gauge")
+	fmt.Fprintf(w, "gep_proxy_working %d\n", m.working)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,114 @@
+package prox
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// CertAuthority mints short-lived, per-host leaf certificates signed by a
+// single root, so a Gateway can terminate TLS for CONNECT requests and
+// inspect the decrypted traffic. Leaves are cached for reuse across
+// connections to the same host.
+type CertAuthority struct {
+	rootCert *x509.Certificate
+	rootKey  *ecdsa.PrivateKey
+
+	mu    sync.Mutex
+	cache map[string]*tls.Certificate
+}
+
+// NewCertAuthority generates a fresh, self-signed root CA good for
+// validYears years.
+func NewCertAuthority(validYears int) (*CertAuthority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("mitm: generate CA key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("mitm: generate CA serial: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "gep MITM CA", Organization: []string{"gep"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(validYears, 0, 0),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("mitm: create CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("mitm: parse CA certificate: %w", err)
+	}
+
+	return &CertAuthority{
+		rootCert: cert,
+		rootKey:  key,
+		cache:    make(map[string]*tls.Certificate),
+	}, nil
+}
+
+// RootCertPEM returns the CA certificate in PEM form, for clients to
+// import and trust.
+func (ca *CertAuthority) RootCertPEM() []byte {
+	return pemEncode("CERTIFICATE", ca.rootCert.Raw)
+}
+
+// LeafFor returns a certificate for host, minting and caching one signed
+// by the root CA if none exists yet.
+func (ca *CertAuthority) LeafFor(host string) (*tls.Certificate, error) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	if cert, ok := ca.cache[host]; ok {
+		return cert, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("mitm: generate leaf key for %s: %w", host, err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("mitm: generate leaf serial for %s: %w", host, err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(0, 0, 30),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{host},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.rootCert, &key.PublicKey, ca.rootKey)
+	if err != nil {
+		return nil, fmt.Errorf("mitm: sign leaf for %s: %w", host, err)
+	}
+
+	cert := &tls.Certificate{
+		Certificate: [][]byte{der, ca.rootCert.Raw},
+		PrivateKey:  key,
+	}
+	ca.cache[host] = cert
+	return cert, nil
+}
@@ -0,0 +1,221 @@
+package prox
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// mmdb data types, per https://maxmind.github.io/MaxMind-DB/#Data_Format.
+// Types >= 8 only appear with the "extended" control byte (type field 0).
+const (
+	mmdbPointer = 1
+	mmdbString  = 2
+	mmdbDouble  = 3
+	mmdbBytes   = 4
+	mmdbUint16  = 5
+	mmdbUint32  = 6
+	mmdbMap     = 7
+	mmdbInt32   = 8
+	mmdbUint64  = 9
+	mmdbUint128 = 10
+	mmdbArray   = 11
+	mmdbBoolean = 14
+	mmdbFloat   = 15
+)
+
+// decoder decodes values out of an MaxMind DB data section. It holds no
+// state of its own beyond the backing bytes, so a single instance can be
+// reused for any offset.
+type decoder struct {
+	data []byte
+}
+
+// decode reads one value starting at offset and returns it along with the
+// offset of the byte following it. Maps decode to map[string]any, arrays
+// to []any, matching encoding/json's conventions so callers can use type
+// switches they already know.
+func (d *decoder) decode(offset int) (any, int, error) {
+	if offset >= len(d.data) {
+		return nil, 0, fmt.Errorf("offset %d out of range", offset)
+	}
+
+	ctrl := d.data[offset]
+	offset++
+	typ := int(ctrl >> 5)
+
+	if typ == 0 {
+		// Extended type: the real type is 8 + the next byte.
+		if offset >= len(d.data) {
+			return nil, 0, fmt.Errorf("truncated extended type at %d", offset)
+		}
+		typ = 8 + int(d.data[offset])
+		offset++
+	}
+
+	if typ == mmdbPointer {
+		return d.decodePointer(ctrl, offset)
+	}
+
+	size, offset, err := d.decodeSize(ctrl, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch typ {
+	case mmdbMap:
+		return d.decodeMap(size, offset)
+	case mmdbArray:
+		return d.decodeArray(size, offset)
+	case mmdbString:
+		if offset+size > len(d.data) {
+			return nil, 0, fmt.Errorf("truncated string at %d", offset)
+		}
+		return string(d.data[offset : offset+size]), offset + size, nil
+	case mmdbBytes:
+		if offset+size > len(d.data) {
+			return nil, 0, fmt.Errorf("truncated bytes at %d", offset)
+		}
+		return append([]byte(nil), d.data[offset:offset+size]...), offset + size, nil
+	case mmdbUint16:
+		return uint16(decodeUint(d.data, offset, size)), offset + size, nil
+	case mmdbUint32:
+		return uint32(decodeUint(d.data, offset, size)), offset + size, nil
+	case mmdbUint64:
+		return decodeUint(d.data, offset, size), offset + size, nil
+	case mmdbUint128:
+		// gep never needs a full uint128; the low 64 bits are plenty to
+		// round-trip the small values MaxMind actually stores here.
+		return decodeUint(d.data, offset, size), offset + size, nil
+	case mmdbInt32:
+		return int32(decodeUint(d.data, offset, size)), offset + size, nil
+	case mmdbDouble:
+		if size != 8 || offset+size > len(d.data) {
+			return nil, 0, fmt.Errorf("invalid double at %d", offset)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(d.data[offset : offset+8])), offset + 8, nil
+	case mmdbFloat:
+		if size != 4 || offset+size > len(d.data) {
+			return nil, 0, fmt.Errorf("invalid float at %d", offset)
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(d.data[offset : offset+4])), offset + 4, nil
+	case mmdbBoolean:
+		return size != 0, offset, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported mmdb data type %d at %d", typ, offset)
+	}
+}
+
+// decodeSize reads the (possibly extended) size field out of ctrl and the
+// bytes following it.
+func (d *decoder) decodeSize(ctrl byte, offset int) (int, int, error) {
+	size := int(ctrl & 0x1F)
+	switch {
+	case size < 29:
+		return size, offset, nil
+	case size == 29:
+		if offset >= len(d.data) {
+			return 0, 0, fmt.Errorf("truncated size at %d", offset)
+		}
+		return 29 + int(d.data[offset]), offset + 1, nil
+	case size == 30:
+		if offset+2 > len(d.data) {
+			return 0, 0, fmt.Errorf("truncated size at %d", offset)
+		}
+		return 285 + int(binary.BigEndian.Uint16(d.data[offset:offset+2])), offset + 2, nil
+	default:
+		if offset+3 > len(d.data) {
+			return 0, 0, fmt.Errorf("truncated size at %d", offset)
+		}
+		n := uint32(d.data[offset])<<16 | uint32(d.data[offset+1])<<8 | uint32(d.data[offset+2])
+		return 65821 + int(n), offset + 3, nil
+	}
+}
+
+// decodePointer reads a pointer value, which encodes both its own size
+// (0-3) and (for sizes 0-2) the top bits of the target offset in ctrl.
+func (d *decoder) decodePointer(ctrl byte, offset int) (any, int, error) {
+	size := (ctrl >> 3) & 0x3
+	var target int
+
+	switch size {
+	case 0:
+		if offset+1 > len(d.data) {
+			return nil, 0, fmt.Errorf("truncated pointer at %d", offset)
+		}
+		target = int(ctrl&0x7)<<8 | int(d.data[offset])
+		offset++
+	case 1:
+		if offset+2 > len(d.data) {
+			return nil, 0, fmt.Errorf("truncated pointer at %d", offset)
+		}
+		target = int(ctrl&0x7)<<16 | int(d.data[offset])<<8 | int(d.data[offset+1])
+		target += 2048
+		offset += 2
+	case 2:
+		if offset+3 > len(d.data) {
+			return nil, 0, fmt.Errorf("truncated pointer at %d", offset)
+		}
+		target = int(ctrl&0x7)<<24 | int(d.data[offset])<<16 | int(d.data[offset+1])<<8 | int(d.data[offset+2])
+		target += 526336
+		offset += 3
+	default:
+		if offset+4 > len(d.data) {
+			return nil, 0, fmt.Errorf("truncated pointer at %d", offset)
+		}
+		target = int(binary.BigEndian.Uint32(d.data[offset : offset+4]))
+		offset += 4
+	}
+
+	value, _, err := d.decode(target)
+	if err != nil {
+		return nil, 0, err
+	}
+	return value, offset, nil
+}
+
+func (d *decoder) decodeMap(size, offset int) (any, int, error) {
+	m := make(map[string]any, size)
+	for i := 0; i < size; i++ {
+		key, next, err := d.decode(offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		keyStr, ok := key.(string)
+		if !ok {
+			return nil, 0, fmt.Errorf("map key at %d is not a string", offset)
+		}
+		value, next, err := d.decode(next)
+		if err != nil {
+			return nil, 0, err
+		}
+		m[keyStr] = value
+		offset = next
+	}
+	return m, offset, nil
+}
+
+func (d *decoder) decodeArray(size, offset int) (any, int, error) {
+	arr := make([]any, size)
+	for i := 0; i < size; i++ {
+		value, next, err := d.decode(offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		arr[i] = value
+		offset = next
+	}
+	return arr, offset, nil
+}
+
+// decodeUint reads a big-endian unsigned integer of size bytes (0-8)
+// starting at offset. MaxMind DB integers may be shorter than their
+// nominal type width when the value fits, so size is not assumed to
+// match the Go return type's width.
+func decodeUint(data []byte, offset, size int) uint64 {
+	var v uint64
+	for i := 0; i < size && offset+i < len(data); i++ {
+		v = v<<8 | uint64(data[offset+i])
+	}
+	return v
+}
@@ -0,0 +1,64 @@
+package prox
+
+import "testing"
+
+func TestDecodeMapOfStrings(t *testing.T) {
+	// {"country": "US"}, hand-encoded per the MaxMind DB data format.
+	data := []byte{
+		0xE1,                                    // map, size 1
+		0x47, 'c', 'o', 'u', 'n', 't', 'r', 'y', // string "country"
+		0x42, 'U', 'S', // string "US"
+	}
+
+	d := &decoder{data: data}
+	value, offset, err := d.decode(0)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if offset != len(data) {
+		t.Fatalf("offset = %d, want %d", offset, len(data))
+	}
+
+	m, ok := value.(map[string]any)
+	if !ok {
+		t.Fatalf("value is %T, want map[string]any", value)
+	}
+	if m["country"] != "US" {
+		t.Fatalf("country = %v, want %q", m["country"], "US")
+	}
+}
+
+func TestDecodeUint32(t *testing.T) {
+	// uint32 1234, stored in the minimum 2 bytes it fits in.
+	data := []byte{0xC2, 0x04, 0xD2}
+
+	d := &decoder{data: data}
+	value, offset, err := d.decode(0)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if offset != len(data) {
+		t.Fatalf("offset = %d, want %d", offset, len(data))
+	}
+	if value != uint32(1234) {
+		t.Fatalf("value = %v, want 1234", value)
+	}
+}
+
+func TestDecodePointer(t *testing.T) {
+	// A size-0 pointer at offset 0 targeting the string "US" at offset 3.
+	data := []byte{
+		0x20, 0x03, // pointer -> offset 3
+		0x00, // padding so the target offset below lines up
+		0x42, 'U', 'S',
+	}
+
+	d := &decoder{data: data}
+	value, _, err := d.decode(0)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if value != "US" {
+		t.Fatalf("value = %v, want %q", value, "US")
+	}
+}
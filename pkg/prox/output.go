@@ -0,0 +1,180 @@
+package prox
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// OutputFormat selects how a ResultWriter renders Results.
+type OutputFormat string
+
+const (
+	OutputTable OutputFormat = "table"
+	OutputJSON  OutputFormat = "json"
+	OutputJSONL OutputFormat = "jsonl"
+	OutputCSV   OutputFormat = "csv"
+)
+
+// ResultWriter streams Results out in some format, one at a time, so a
+// caller can write each Result as it comes off a TestProxiesStream
+// channel instead of buffering the whole batch in memory. Close must be
+// called exactly once after the last Write, to flush any trailing output
+// the format needs (e.g. a closing JSON bracket).
+type ResultWriter interface {
+	Write(Result) error
+	Close() error
+}
+
+// NewResultWriter returns a ResultWriter for format, writing to w. An
+// empty format is equivalent to OutputTable.
+func NewResultWriter(w io.Writer, format OutputFormat) (ResultWriter, error) {
+	switch format {
+	case "", OutputTable:
+		return &tableWriter{w: w}, nil
+	case OutputJSON:
+		return &jsonWriter{w: w}, nil
+	case OutputJSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	case OutputCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("prox: unknown output format %q", format)
+	}
+}
+
+// jsonWriter renders Results as a single JSON array, so -o results.json
+// is parseable as one document once Close has run.
+type jsonWriter struct {
+	w io.Writer
+	n int
+}
+
+func (jw *jsonWriter) Write(result Result) error {
+	sep := ","
+	if jw.n == 0 {
+		sep = "["
+	}
+	jw.n++
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(jw.w, "%s\n  %s", sep, data)
+	return err
+}
+
+func (jw *jsonWriter) Close() error {
+	if jw.n == 0 {
+		_, err := fmt.Fprintln(jw.w, "[]")
+		return err
+	}
+	_, err := fmt.Fprintln(jw.w, "\n]")
+	return err
+}
+
+// jsonlWriter renders Results as newline-delimited JSON, one object per
+// line, so a consumer can process the output as it streams in without
+// waiting for Close.
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func (jw *jsonlWriter) Write(result Result) error { return jw.enc.Encode(result) }
+func (jw *jsonlWriter) Close() error              { return nil }
+
+// csvWriter renders Results as CSV, writing the header row before the
+// first Result.
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+var csvHeader = []string{
+	"proxy", "scheme", "working", "ip", "duration_seconds", "error",
+	"anonymity", "country", "asn", "org",
+}
+
+func (cw *csvWriter) Write(result Result) error {
+	if !cw.wroteHeader {
+		if err := cw.w.Write(csvHeader); err != nil {
+			return err
+		}
+		cw.wroteHeader = true
+	}
+	return cw.w.Write([]string{
+		result.Proxy,
+		result.Scheme,
+		strconv.FormatBool(result.IsWorking),
+		result.IP,
+		strconv.FormatFloat(result.Duration.Seconds(), 'f', 3, 64),
+		result.Error,
+		string(result.Anonymity),
+		result.Country,
+		result.ASN,
+		result.Org,
+	})
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// tableWriter renders Results as the fixed-width human-readable table gep
+// has always printed, tallying a working/total summary as Results arrive
+// so it can report it on Close without a second pass.
+type tableWriter struct {
+	w           io.Writer
+	wroteHeader bool
+	total       int
+	working     int
+}
+
+func (tw *tableWriter) Write(result Result) error {
+	if !tw.wroteHeader {
+		fmt.Fprintf(tw.w, "\n%-50s %-10s %-15s %-10s %-12s %-7s %s\n",
+			"PROXY", "STATUS", "IP", "TIME", "ANONYMITY", "COUNTRY", "ERROR")
+		fmt.Fprintln(tw.w, strings.Repeat("-", 110))
+		tw.wroteHeader = true
+	}
+
+	tw.total++
+	status := "FAILED"
+	if result.IsWorking {
+		status = "WORKING"
+		tw.working++
+	}
+
+	_, err := fmt.Fprintf(tw.w, "%-50s %-10s %-15s %-10s %-12s %-7s %s\n",
+		truncateString(result.Proxy, 50),
+		status,
+		result.IP,
+		fmt.Sprintf("%.2fs", result.Duration.Seconds()),
+		result.Anonymity,
+		result.Country,
+		result.Error)
+	return err
+}
+
+func (tw *tableWriter) Close() error {
+	if tw.total == 0 {
+		_, err := fmt.Fprintln(tw.w, "\nSummary: no proxies matched the requested filters")
+		return err
+	}
+	_, err := fmt.Fprintf(tw.w, "\nSummary: %d/%d proxies working (%.1f%%)\n",
+		tw.working, tw.total, float64(tw.working)/float64(tw.total)*100)
+	return err
+}
+
+// truncateString truncates s to at most maxLen characters, replacing the
+// tail with "..." when it doesn't fit.
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen-3] + "..."
+}
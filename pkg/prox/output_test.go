@@ -0,0 +1,88 @@
+package prox
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONLWriterEncodesOnePerLine(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewResultWriter(&buf, OutputJSONL)
+	if err != nil {
+		t.Fatalf("NewResultWriter: %v", err)
+	}
+
+	results := []Result{
+		{Proxy: "a:1", IsWorking: true, Duration: 100 * time.Millisecond},
+		{Proxy: "b:2", IsWorking: false, Error: "timeout"},
+	}
+	for _, r := range results {
+		if err := w.Write(r); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(results) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(results))
+	}
+	for i, line := range lines {
+		var got Result
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if got.Proxy != results[i].Proxy {
+			t.Errorf("line %d: proxy = %q, want %q", i, got.Proxy, results[i].Proxy)
+		}
+	}
+}
+
+func TestJSONWriterProducesOneArray(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewResultWriter(&buf, OutputJSON)
+	if err != nil {
+		t.Fatalf("NewResultWriter: %v", err)
+	}
+
+	w.Write(Result{Proxy: "a:1", IsWorking: true})
+	w.Write(Result{Proxy: "b:2", IsWorking: false})
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	var got []Result
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a single JSON array: %v\n%s", err, buf.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d results, want 2", len(got))
+	}
+}
+
+func TestCSVWriterWritesHeaderOnce(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewResultWriter(&buf, OutputCSV)
+	if err != nil {
+		t.Fatalf("NewResultWriter: %v", err)
+	}
+
+	w.Write(Result{Proxy: "a:1", IsWorking: true})
+	w.Write(Result{Proxy: "b:2", IsWorking: false})
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines (header + 2 rows), want 3:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "proxy,scheme,working") {
+		t.Errorf("header = %q, want it to start with proxy,scheme,working", lines[0])
+	}
+}
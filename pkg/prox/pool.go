@@ -0,0 +1,199 @@
+package prox
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Strategy selects which upstream proxy in a Pool to use for the next
+// request.
+type Strategy string
+
+const (
+	// StrategyRoundRobin cycles through healthy upstreams in order.
+	StrategyRoundRobin Strategy = "round-robin"
+	// StrategyRandom picks a healthy upstream uniformly at random.
+	StrategyRandom Strategy = "random"
+	// StrategyLeastLatency picks the healthy upstream with the lowest
+	// last-measured check latency.
+	StrategyLeastLatency Strategy = "least-latency"
+	// StrategyStickyByClientIP hashes the requesting client's IP to a
+	// healthy upstream, so the same client keeps using the same proxy.
+	StrategyStickyByClientIP Strategy = "sticky-by-client-ip"
+)
+
+// ErrNoHealthyProxies is returned by Pool.Select when there is no
+// upstream available to route a request through.
+var ErrNoHealthyProxies = errors.New("prox: no healthy proxies available")
+
+// poolEntry tracks the health of a single upstream proxy.
+type poolEntry struct {
+	proxy               string
+	scheme              string
+	latency             time.Duration
+	consecutiveFailures int
+	healthy             bool
+}
+
+// Pool holds a set of upstream proxies along with their health, and picks
+// one to route a request through according to a Strategy. It is safe for
+// concurrent use.
+type Pool struct {
+	mu          sync.Mutex
+	entries     []*poolEntry
+	strategy    Strategy
+	maxFailures int
+	rrCounter   uint64
+}
+
+// NewPool creates a Pool that evicts an upstream after maxFailures
+// consecutive failed checks. maxFailures <= 0 means an upstream is never
+// evicted automatically.
+func NewPool(strategy Strategy, maxFailures int) *Pool {
+	return &Pool{
+		strategy:    strategy,
+		maxFailures: maxFailures,
+	}
+}
+
+// Seed registers proxies with the pool as healthy, without waiting for a
+// check to confirm them. Typically followed by reporting real Results as
+// checks complete.
+func (p *Pool) Seed(proxies []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, proxy := range proxies {
+		p.entries = append(p.entries, &poolEntry{proxy: proxy, healthy: true})
+	}
+}
+
+// Reset discards every known entry and reseeds the pool from proxies,
+// marked healthy, as Seed would on an empty Pool. Use it to replace the
+// pool's proxy list wholesale (e.g. on a config reload), dropping
+// upstreams no longer in the list instead of carrying their stale health
+// state forward forever.
+func (p *Pool) Reset(proxies []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.entries = make([]*poolEntry, 0, len(proxies))
+	for _, proxy := range proxies {
+		p.entries = append(p.entries, &poolEntry{proxy: proxy, healthy: true})
+	}
+}
+
+// ReportResult updates (or adds) the pool entry for result.Proxy based on
+// the outcome of a check, evicting it once it has failed maxFailures times
+// in a row.
+func (p *Pool) ReportResult(result Result) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry := p.findOrAddLocked(result.Proxy)
+	if result.IsWorking {
+		entry.healthy = true
+		entry.scheme = result.Scheme
+		entry.latency = result.Duration
+		entry.consecutiveFailures = 0
+		return
+	}
+
+	entry.consecutiveFailures++
+	if p.maxFailures > 0 && entry.consecutiveFailures >= p.maxFailures {
+		entry.healthy = false
+	}
+}
+
+func (p *Pool) findOrAddLocked(proxy string) *poolEntry {
+	for _, entry := range p.entries {
+		if entry.proxy == proxy {
+			return entry
+		}
+	}
+	entry := &poolEntry{proxy: proxy}
+	p.entries = append(p.entries, entry)
+	return entry
+}
+
+// Healthy returns the proxy URLs currently considered healthy.
+func (p *Pool) Healthy() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	healthy := make([]string, 0, len(p.entries))
+	for _, entry := range p.entries {
+		if entry.healthy {
+			healthy = append(healthy, entry.proxy)
+		}
+	}
+	return healthy
+}
+
+// All returns every proxy URL known to the pool, healthy or not.
+func (p *Pool) All() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	all := make([]string, len(p.entries))
+	for i, entry := range p.entries {
+		all[i] = entry.proxy
+	}
+	return all
+}
+
+// Select picks a healthy upstream according to the pool's Strategy.
+// clientAddr (as in net/http's Request.RemoteAddr) is only consulted by
+// StrategyStickyByClientIP.
+func (p *Pool) Select(clientAddr string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	healthy := make([]*poolEntry, 0, len(p.entries))
+	for _, entry := range p.entries {
+		if entry.healthy {
+			healthy = append(healthy, entry)
+		}
+	}
+	if len(healthy) == 0 {
+		return "", ErrNoHealthyProxies
+	}
+
+	switch p.strategy {
+	case StrategyRandom:
+		return healthy[rand.Intn(len(healthy))].proxy, nil
+	case StrategyLeastLatency:
+		best := healthy[0]
+		for _, entry := range healthy[1:] {
+			if entry.latency > 0 && (best.latency == 0 || entry.latency < best.latency) {
+				best = entry
+			}
+		}
+		return best.proxy, nil
+	case StrategyStickyByClientIP:
+		host, _, err := net.SplitHostPort(clientAddr)
+		if err != nil {
+			host = clientAddr
+		}
+		return healthy[stickyHash(host)%uint32(len(healthy))].proxy, nil
+	case StrategyRoundRobin, "":
+		n := atomic.AddUint64(&p.rrCounter, 1) - 1
+		return healthy[int(n%uint64(len(healthy)))].proxy, nil
+	default:
+		return healthy[0].proxy, nil
+	}
+}
+
+// stickyHash is a small, fast, non-cryptographic string hash (FNV-1a).
+func stickyHash(s string) uint32 {
+	var h uint32 = 2166136261
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h
+}
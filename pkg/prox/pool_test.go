@@ -0,0 +1,78 @@
+package prox
+
+import "testing"
+
+func TestPoolEvictsAfterMaxFailures(t *testing.T) {
+	pool := NewPool(StrategyRoundRobin, 2)
+	pool.Seed([]string{"http://a:1"})
+
+	pool.ReportResult(Result{Proxy: "http://a:1", IsWorking: false})
+	if _, err := pool.Select(""); err != nil {
+		t.Fatalf("proxy evicted too early: %v", err)
+	}
+
+	pool.ReportResult(Result{Proxy: "http://a:1", IsWorking: false})
+	if _, err := pool.Select(""); err != ErrNoHealthyProxies {
+		t.Fatalf("expected ErrNoHealthyProxies after %d failures, got %v", 2, err)
+	}
+}
+
+func TestPoolRoundRobinCyclesThroughAll(t *testing.T) {
+	pool := NewPool(StrategyRoundRobin, 0)
+	pool.Seed([]string{"http://a:1", "http://b:1", "http://c:1"})
+
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		p, err := pool.Select("")
+		if err != nil {
+			t.Fatalf("Select returned error: %v", err)
+		}
+		seen[p] = true
+	}
+	if len(seen) != 3 {
+		t.Fatalf("round-robin did not cycle through all proxies: %v", seen)
+	}
+}
+
+func TestPoolStickyByClientIPIsStable(t *testing.T) {
+	pool := NewPool(StrategyStickyByClientIP, 0)
+	pool.Seed([]string{"http://a:1", "http://b:1", "http://c:1"})
+
+	first, err := pool.Select("203.0.113.5:54321")
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		next, err := pool.Select("203.0.113.5:9999")
+		if err != nil {
+			t.Fatalf("Select returned error: %v", err)
+		}
+		if next != first {
+			t.Fatalf("sticky selection changed: got %q, want %q", next, first)
+		}
+	}
+}
+
+func TestPoolSelectWithNoProxiesErrors(t *testing.T) {
+	pool := NewPool(StrategyRoundRobin, 0)
+	if _, err := pool.Select(""); err != ErrNoHealthyProxies {
+		t.Fatalf("expected ErrNoHealthyProxies, got %v", err)
+	}
+}
+
+func TestPoolResetDropsProxiesNoLongerListed(t *testing.T) {
+	pool := NewPool(StrategyRoundRobin, 1)
+	pool.Seed([]string{"http://a:1", "http://b:1"})
+	pool.ReportResult(Result{Proxy: "http://b:1", IsWorking: false}) // evict b
+
+	pool.Reset([]string{"http://a:1", "http://c:1"})
+
+	all := pool.All()
+	if len(all) != 2 {
+		t.Fatalf("All() = %v, want exactly the reset list", all)
+	}
+	healthy := pool.Healthy()
+	if len(healthy) != 2 {
+		t.Fatalf("Healthy() = %v, want both reset proxies healthy again", healthy)
+	}
+}
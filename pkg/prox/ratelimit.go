@@ -0,0 +1,84 @@
+package prox
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a classic token-bucket rate limiter: tokens accrue at
+// refillPerSec up to capacity, and Wait blocks until one is available.
+type tokenBucket struct {
+	mu           sync.Mutex
+	tokens       float64
+	capacity     float64
+	refillPerSec float64
+	last         time.Time
+}
+
+func newTokenBucket(qps float64) *tokenBucket {
+	return &tokenBucket{tokens: qps, capacity: qps, refillPerSec: qps, last: time.Now()}
+}
+
+// wait blocks until a token is available or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.refillPerSec
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration(float64(time.Second) * (1 - b.tokens) / b.refillPerSec)
+		b.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// HostRateLimiter caps requests per second separately for each host, so a
+// Tester or Checker hammering several check endpoints doesn't exceed any
+// one of them regardless of how many others it's also calling.
+type HostRateLimiter struct {
+	mu      sync.Mutex
+	qps     float64
+	buckets map[string]*tokenBucket
+}
+
+// NewHostRateLimiter creates a HostRateLimiter allowing qps requests per
+// second to each distinct host. qps <= 0 disables limiting: Wait always
+// returns immediately.
+func NewHostRateLimiter(qps float64) *HostRateLimiter {
+	return &HostRateLimiter{qps: qps, buckets: make(map[string]*tokenBucket)}
+}
+
+// Wait blocks until host has a free slot under its per-host QPS budget, or
+// ctx is done.
+func (h *HostRateLimiter) Wait(ctx context.Context, host string) error {
+	if h == nil || h.qps <= 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	bucket, ok := h.buckets[host]
+	if !ok {
+		bucket = newTokenBucket(h.qps)
+		h.buckets[host] = bucket
+	}
+	h.mu.Unlock()
+
+	return bucket.wait(ctx)
+}
@@ -0,0 +1,48 @@
+package prox
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHostRateLimiterDisabledWhenQPSIsZero(t *testing.T) {
+	limiter := NewHostRateLimiter(0)
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := limiter.Wait(context.Background(), "example.com"); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("Wait with qps=0 took %v, want effectively instant", elapsed)
+	}
+}
+
+func TestHostRateLimiterLimitsPerHostIndependently(t *testing.T) {
+	limiter := NewHostRateLimiter(1000) // generous enough not to block within the burst below
+
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if err := limiter.Wait(ctx, "a.example"); err != nil {
+			t.Fatalf("Wait a.example: %v", err)
+		}
+		if err := limiter.Wait(ctx, "b.example"); err != nil {
+			t.Fatalf("Wait b.example: %v", err)
+		}
+	}
+}
+
+func TestHostRateLimiterRespectsContextCancellation(t *testing.T) {
+	limiter := NewHostRateLimiter(1)
+	ctx := context.Background()
+	if err := limiter.Wait(ctx, "throttled.example"); err != nil {
+		t.Fatalf("first Wait: %v", err)
+	}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(cancelCtx, "throttled.example"); err == nil {
+		t.Fatal("Wait with a canceled context returned nil error, want context.Canceled")
+	}
+}
@@ -0,0 +1,23 @@
+package prox
+
+import "time"
+
+// Result holds the outcome of checking a single proxy.
+type Result struct {
+	Proxy     string
+	Scheme    string
+	IsWorking bool
+	IP        string
+	Error     string
+	Duration  time.Duration
+
+	// Anonymity classifies how much a working proxy reveals about the
+	// original client. Left empty when it could not be determined.
+	Anonymity AnonymityLevel
+
+	// Country, ASN and Org are populated from a GeoProvider when the
+	// Checker has one configured. Left empty otherwise.
+	Country string
+	ASN     string
+	Org     string
+}
@@ -0,0 +1,54 @@
+package prox
+
+import (
+	"context"
+	"time"
+)
+
+// Revalidator periodically re-checks every proxy known to a Pool and
+// reports the outcome back to it, so the pool's health view stays fresh
+// while a Gateway is serving traffic.
+type Revalidator struct {
+	Pool     *Pool
+	Tester   *Tester
+	Interval time.Duration
+
+	// Metrics, when set, has its gep_proxy_working gauge refreshed from
+	// Pool.Healthy after every revalidation pass.
+	Metrics *Metrics
+}
+
+// NewRevalidator creates a Revalidator that re-tests pool's proxies with
+// tester every interval.
+func NewRevalidator(pool *Pool, tester *Tester, interval time.Duration) *Revalidator {
+	return &Revalidator{Pool: pool, Tester: tester, Interval: interval}
+}
+
+// Run blocks, re-validating the pool every Interval, until ctx is
+// canceled.
+func (r *Revalidator) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.revalidate()
+		}
+	}
+}
+
+func (r *Revalidator) revalidate() {
+	proxies := r.Pool.All()
+	if len(proxies) == 0 {
+		return
+	}
+	for _, result := range r.Tester.TestProxies(proxies) {
+		r.Pool.ReportResult(result)
+	}
+	if r.Metrics != nil {
+		r.Metrics.SetWorking(len(r.Pool.Healthy()))
+	}
+}
@@ -0,0 +1,152 @@
+package prox
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scoreboard is a point-in-time snapshot of a Tester's adaptive worker
+// pool, so callers can observe concurrency and backpressure while a test
+// run is in flight.
+type Scoreboard struct {
+	// Workers is the pool's current concurrency cap, somewhere between
+	// MinWorkers and MaxWorkers depending on recent outcomes.
+	Workers    int
+	MinWorkers int
+	MaxWorkers int
+	// InFlight is how many checks are running right now.
+	InFlight int
+	// Succeeded, Failed and Throttled count checks since the Tester was
+	// created: Throttled is checks classified as a sign of the target
+	// struggling (timeout, 5xx, 429) rather than a simple dead proxy.
+	Succeeded int64
+	Failed    int64
+	Throttled int64
+	// AvgLatency is an exponentially-weighted average over successful
+	// checks, used to gate growth on latency as well as success rate.
+	AvgLatency time.Duration
+}
+
+// checkOutcome classifies a finished check for the AIMD controller below.
+type checkOutcome int
+
+const (
+	outcomeFail checkOutcome = iota
+	outcomeSuccess
+	outcomeThrottled
+)
+
+// aimdGrowAfter is how many consecutive fast successes it takes to grow
+// the worker pool by one: the additive-increase half of AIMD.
+const aimdGrowAfter = 5
+
+// adaptivePool gates check concurrency with an AIMD controller: it grows
+// by one worker after aimdGrowAfter consecutive successes whose latency
+// stayed near the rolling average, and halves immediately ("multiplicative
+// decrease") on a timeout or 5xx/429, so a test run backs off quickly from
+// a struggling target and recovers gradually rather than bouncing between
+// extremes.
+type adaptivePool struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	cur, min, max int
+	inFlight      int
+	successStreak int
+	avgLatency    float64 // seconds, EWMA over successes only
+
+	succeeded, failed, throttled int64
+}
+
+// newAdaptivePool creates a pool starting at full (max) concurrency: the
+// configured worker count is a known-good starting point, and the AIMD
+// controller only needs to shrink it if the target turns out to be
+// struggling, rather than ramping up from scratch on every run.
+func newAdaptivePool(min, max int) *adaptivePool {
+	p := &adaptivePool{cur: max, min: min, max: max}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// acquire blocks until a slot is free under the pool's current (possibly
+// shrunk) capacity.
+func (p *adaptivePool) acquire() {
+	p.mu.Lock()
+	for p.inFlight >= p.cur {
+		p.cond.Wait()
+	}
+	p.inFlight++
+	p.mu.Unlock()
+}
+
+// release returns the slot acquire gave out and folds result into the
+// AIMD controller.
+func (p *adaptivePool) release(result Result) {
+	p.mu.Lock()
+	p.inFlight--
+
+	switch classifyOutcome(result) {
+	case outcomeSuccess:
+		p.succeeded++
+		seconds := result.Duration.Seconds()
+		if p.avgLatency == 0 {
+			p.avgLatency = seconds
+		} else {
+			p.avgLatency = p.avgLatency*0.8 + seconds*0.2
+		}
+		if seconds <= p.avgLatency*1.5 {
+			p.successStreak++
+			if p.successStreak >= aimdGrowAfter && p.cur < p.max {
+				p.cur++
+				p.successStreak = 0
+			}
+		} else {
+			p.successStreak = 0
+		}
+	case outcomeThrottled:
+		p.throttled++
+		p.successStreak = 0
+		p.cur /= 2
+		if p.cur < p.min {
+			p.cur = p.min
+		}
+	default:
+		p.failed++
+	}
+
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
+
+func (p *adaptivePool) snapshot() Scoreboard {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Scoreboard{
+		Workers:    p.cur,
+		MinWorkers: p.min,
+		MaxWorkers: p.max,
+		InFlight:   p.inFlight,
+		Succeeded:  p.succeeded,
+		Failed:     p.failed,
+		Throttled:  p.throttled,
+		AvgLatency: time.Duration(p.avgLatency * float64(time.Second)),
+	}
+}
+
+// classifyOutcome buckets a finished check for the AIMD controller: a
+// timeout or server error (5xx/429) signals the target itself is
+// struggling and should shrink concurrency, while any other failure
+// (refused connection, bad proxy, ...) says nothing about the target and
+// is treated as neutral.
+func classifyOutcome(result Result) checkOutcome {
+	if result.IsWorking {
+		return outcomeSuccess
+	}
+	if strings.Contains(result.Error, "HTTP 5") ||
+		strings.Contains(result.Error, "HTTP 429") ||
+		strings.Contains(strings.ToLower(result.Error), "timeout") {
+		return outcomeThrottled
+	}
+	return outcomeFail
+}
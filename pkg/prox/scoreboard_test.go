@@ -0,0 +1,57 @@
+package prox
+
+import "testing"
+
+func TestAdaptivePoolShrinksOnThrottle(t *testing.T) {
+	p := newAdaptivePool(1, 8)
+	p.acquire()
+	p.release(Result{IsWorking: false, Error: "HTTP 503"})
+
+	snap := p.snapshot()
+	if snap.Workers != 4 {
+		t.Fatalf("Workers = %d, want 4 (halved from 8)", snap.Workers)
+	}
+	if snap.Throttled != 1 {
+		t.Fatalf("Throttled = %d, want 1", snap.Throttled)
+	}
+}
+
+func TestAdaptivePoolNeverShrinksBelowMin(t *testing.T) {
+	p := newAdaptivePool(2, 3)
+	for i := 0; i < 3; i++ {
+		p.acquire()
+		p.release(Result{IsWorking: false, Error: "request timeout"})
+	}
+
+	if got := p.snapshot().Workers; got != 2 {
+		t.Fatalf("Workers = %d, want min 2", got)
+	}
+}
+
+func TestAdaptivePoolGrowsAfterStreakOfFastSuccesses(t *testing.T) {
+	p := newAdaptivePool(1, 2)
+	p.cur = 1 // start shrunk, so growth back to max is observable
+
+	for i := 0; i < aimdGrowAfter; i++ {
+		p.acquire()
+		p.release(Result{IsWorking: true, Duration: 100_000_000}) // 100ms, stable
+	}
+
+	if got := p.snapshot().Workers; got != 2 {
+		t.Fatalf("Workers = %d, want 2 after %d fast successes", got, aimdGrowAfter)
+	}
+}
+
+func TestAdaptivePoolOrdinaryFailureIsNeutral(t *testing.T) {
+	p := newAdaptivePool(1, 8)
+	p.acquire()
+	p.release(Result{IsWorking: false, Error: "connection refused"})
+
+	snap := p.snapshot()
+	if snap.Workers != 8 {
+		t.Fatalf("Workers = %d, want unchanged 8", snap.Workers)
+	}
+	if snap.Failed != 1 || snap.Throttled != 0 {
+		t.Fatalf("Failed=%d Throttled=%d, want Failed=1 Throttled=0", snap.Failed, snap.Throttled)
+	}
+}
@@ -0,0 +1,103 @@
+package prox
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// socks4Dialer returns a dialer that tunnels through a SOCKS4 or SOCKS4a
+// proxy (RFC: SOCKS4.protocol, no official RFC). SOCKS4a is selected by
+// proxyURL.Scheme == "socks4a" and lets the proxy itself resolve the
+// destination hostname instead of requiring the client to resolve it first.
+func socks4Dialer(proxyURL *url.URL, timeout time.Duration) (contextDialFunc, error) {
+	useHostname := proxyURL.Scheme == "socks4a"
+	userID := ""
+	if proxyURL.User != nil {
+		userID = proxyURL.User.Username()
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := (&net.Dialer{Timeout: timeout}).DialContext(ctx, network, proxyURL.Host)
+		if err != nil {
+			return nil, fmt.Errorf("socks4: dial proxy: %w", err)
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+			defer conn.SetDeadline(time.Time{})
+		}
+
+		if err := socks4Connect(conn, addr, userID, useHostname); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}, nil
+}
+
+// socks4Connect performs the SOCKS4/4a CONNECT handshake on an already
+// dialed connection to the proxy.
+func socks4Connect(conn net.Conn, addr, userID string, useHostname bool) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("socks4: invalid target address %q: %w", addr, err)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fmt.Errorf("socks4: invalid target port %q: %w", portStr, err)
+	}
+
+	ip := net.ParseIP(host)
+	if ip != nil {
+		ip = ip.To4()
+	}
+	if ip == nil && !useHostname {
+		return fmt.Errorf("socks4: %q is not an IPv4 address (use socks4a:// to resolve hostnames via the proxy)", host)
+	}
+
+	req := []byte{0x04, 0x01} // version 4, CONNECT
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if ip != nil {
+		req = append(req, ip...)
+	} else {
+		req = append(req, 0, 0, 0, 1) // invalid IP signals SOCKS4a hostname mode
+	}
+	req = append(req, userID...)
+	req = append(req, 0)
+	if ip == nil {
+		req = append(req, host...)
+		req = append(req, 0)
+	}
+
+	if _, err := conn.Write(req); err != nil {
+		return fmt.Errorf("socks4: send request: %w", err)
+	}
+
+	reply := make([]byte, 8)
+	if _, err := readFull(conn, reply); err != nil {
+		return fmt.Errorf("socks4: read reply: %w", err)
+	}
+	if reply[0] != 0x00 {
+		return fmt.Errorf("socks4: malformed reply (version byte %#x)", reply[0])
+	}
+	if reply[1] != 0x5a {
+		return fmt.Errorf("socks4: request rejected or failed (code %#x)", reply[1])
+	}
+	return nil
+}
+
+func readFull(conn net.Conn, buf []byte) (int, error) {
+	n := 0
+	for n < len(buf) {
+		m, err := conn.Read(buf[n:])
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,65 @@
+package prox
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSocks4ConnectIPv4(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- socks4Connect(client, "93.184.216.34:80", "alice", false)
+	}()
+
+	server.SetReadDeadline(time.Now().Add(time.Second))
+	req := make([]byte, 9+len("alice"))
+	if _, err := readFull(server, req); err != nil {
+		t.Fatalf("server read request: %v", err)
+	}
+	if req[0] != 0x04 || req[1] != 0x01 {
+		t.Fatalf("unexpected request header: %v", req[:2])
+	}
+
+	server.SetWriteDeadline(time.Now().Add(time.Second))
+	if _, err := server.Write([]byte{0x00, 0x5a, 0, 0, 0, 0, 0, 0}); err != nil {
+		t.Fatalf("server write reply: %v", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("socks4Connect returned error: %v", err)
+	}
+}
+
+func TestSocks4ConnectRejected(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- socks4Connect(client, "93.184.216.34:80", "", false)
+	}()
+
+	server.SetReadDeadline(time.Now().Add(time.Second))
+	req := make([]byte, 9)
+	if _, err := readFull(server, req); err != nil {
+		t.Fatalf("server read request: %v", err)
+	}
+	server.Write([]byte{0x00, 0x5b, 0, 0, 0, 0, 0, 0})
+
+	if err := <-done; err == nil {
+		t.Fatal("expected error for rejected request, got nil")
+	}
+}
+
+func TestSocks4ConnectRequiresIPv4WithoutHostname(t *testing.T) {
+	_, client := net.Pipe()
+	defer client.Close()
+
+	if err := socks4Connect(client, "example.com:80", "", false); err == nil {
+		t.Fatal("expected error for hostname target without socks4a, got nil")
+	}
+}
@@ -0,0 +1,204 @@
+package prox
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+const (
+	socks5Version    = 0x05
+	socks5CmdConnect = 0x01
+
+	socks5AuthNone     = 0x00
+	socks5AuthUserPass = 0x02
+	socks5AuthNoAccept = 0xff
+
+	socks5AtypIPv4   = 0x01
+	socks5AtypDomain = 0x03
+	socks5AtypIPv6   = 0x04
+)
+
+// SOCKS5Server accepts local SOCKS5 client connections and forwards each
+// one through an upstream selected by dial.
+type SOCKS5Server struct {
+	// dial connects to the requested destination, on behalf of a client,
+	// routed through whichever upstream the Gateway selects.
+	dial func(network, addr string) (net.Conn, error)
+	// Auth, when non-nil, requires clients to authenticate with
+	// username/password verified against it.
+	Auth *Htpasswd
+}
+
+// NewSOCKS5Server creates a SOCKS5Server that forwards CONNECT requests via
+// dial.
+func NewSOCKS5Server(dial func(network, addr string) (net.Conn, error)) *SOCKS5Server {
+	return &SOCKS5Server{dial: dial}
+}
+
+// Serve accepts connections on ln until it returns an error (typically
+// because ln was closed).
+func (s *SOCKS5Server) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *SOCKS5Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	if err := s.negotiate(conn); err != nil {
+		return
+	}
+
+	target, err := s.readRequest(conn)
+	if err != nil {
+		return
+	}
+
+	upstream, err := s.dial("tcp", target)
+	if err != nil {
+		conn.Write([]byte{socks5Version, 0x05, 0x00, socks5AtypIPv4, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer upstream.Close()
+
+	reply := []byte{socks5Version, 0x00, 0x00, socks5AtypIPv4, 0, 0, 0, 0, 0, 0}
+	if _, err := conn.Write(reply); err != nil {
+		return
+	}
+
+	pipeConns(conn, upstream)
+}
+
+// negotiate performs the SOCKS5 method-selection handshake.
+func (s *SOCKS5Server) negotiate(conn net.Conn) error {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if header[0] != socks5Version {
+		return fmt.Errorf("socks5: unsupported version %#x", header[0])
+	}
+
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return err
+	}
+
+	if s.Auth == nil {
+		if _, err := conn.Write([]byte{socks5Version, socks5AuthNone}); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	hasUserPass := false
+	for _, m := range methods {
+		if m == socks5AuthUserPass {
+			hasUserPass = true
+		}
+	}
+	if !hasUserPass {
+		conn.Write([]byte{socks5Version, socks5AuthNoAccept})
+		return fmt.Errorf("socks5: client does not support username/password auth")
+	}
+	if _, err := conn.Write([]byte{socks5Version, socks5AuthUserPass}); err != nil {
+		return err
+	}
+	return s.authenticate(conn)
+}
+
+func (s *SOCKS5Server) authenticate(conn net.Conn) error {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	user := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, user); err != nil {
+		return err
+	}
+
+	passLen := make([]byte, 1)
+	if _, err := io.ReadFull(conn, passLen); err != nil {
+		return err
+	}
+	pass := make([]byte, passLen[0])
+	if _, err := io.ReadFull(conn, pass); err != nil {
+		return err
+	}
+
+	if !s.Auth.Verify(string(user), string(pass)) {
+		conn.Write([]byte{0x01, 0x01})
+		return fmt.Errorf("socks5: authentication failed for %q", user)
+	}
+	_, err := conn.Write([]byte{0x01, 0x00})
+	return err
+}
+
+// readRequest reads a SOCKS5 request and returns its destination as
+// "host:port".
+func (s *SOCKS5Server) readRequest(conn net.Conn) (string, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", err
+	}
+	if header[0] != socks5Version || header[1] != socks5CmdConnect {
+		return "", fmt.Errorf("socks5: unsupported command %#x", header[1])
+	}
+
+	var host string
+	switch header[3] {
+	case socks5AtypIPv4:
+		addr := make([]byte, 4)
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return "", err
+		}
+		host = net.IP(addr).String()
+	case socks5AtypIPv6:
+		addr := make([]byte, 16)
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return "", err
+		}
+		host = net.IP(addr).String()
+	case socks5AtypDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", err
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return "", err
+		}
+		host = string(domain)
+	default:
+		return "", fmt.Errorf("socks5: unsupported address type %#x", header[3])
+	}
+
+	portBuf := make([]byte, 2)
+	if _, err := io.ReadFull(conn, portBuf); err != nil {
+		return "", err
+	}
+	port := binary.BigEndian.Uint16(portBuf)
+
+	return net.JoinHostPort(host, fmt.Sprintf("%d", port)), nil
+}
+
+// pipeConns relays data in both directions until either side closes.
+func pipeConns(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(a, b)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(b, a)
+		done <- struct{}{}
+	}()
+	<-done
+}
@@ -0,0 +1,194 @@
+package prox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// DefaultStorePath is where gep keeps its check history when the caller
+// doesn't configure one explicitly.
+const DefaultStorePath = "~/.gep/gep.db"
+
+// ResolveStorePath expands a leading "~" in path to the current user's
+// home directory, and falls back to DefaultStorePath when path is empty.
+func ResolveStorePath(path string) (string, error) {
+	if path == "" {
+		path = DefaultStorePath
+	}
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving %q: %w", path, err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// SQLiteStore is the default Store, backed by a single SQLite database
+// file via the pure-Go modernc.org/sqlite driver (so gep keeps building
+// with plain `go build`, no cgo toolchain required).
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (creating if necessary) the SQLite database at
+// path, which may use "~" for the user's home directory, and ensures its
+// schema exists.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	path, err := ResolveStorePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("sqlitestore: creating %s: %w", dir, err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("sqlitestore: opening %s: %w", path, err)
+	}
+	// A single file-backed SQLite connection avoids SQLITE_BUSY errors
+	// from concurrent writers stepping on each other.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schemaSQL); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlitestore: creating schema: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+const schemaSQL = `
+CREATE TABLE IF NOT EXISTS checks (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	proxy       TEXT NOT NULL,
+	scheme      TEXT NOT NULL,
+	checked_at  DATETIME NOT NULL,
+	working     INTEGER NOT NULL,
+	ip          TEXT NOT NULL,
+	error       TEXT NOT NULL,
+	duration_ms INTEGER NOT NULL,
+	anonymity   TEXT NOT NULL,
+	country     TEXT NOT NULL,
+	asn         TEXT NOT NULL,
+	org         TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_checks_proxy ON checks(proxy);
+CREATE INDEX IF NOT EXISTS idx_checks_checked_at ON checks(checked_at);
+`
+
+// Record implements Store.
+func (s *SQLiteStore) Record(ctx context.Context, result Result, checkedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO checks (proxy, scheme, checked_at, working, ip, error, duration_ms, anonymity, country, asn, org)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		result.Proxy, result.Scheme, checkedAt, result.IsWorking, result.IP, result.Error,
+		result.Duration.Milliseconds(), string(result.Anonymity), result.Country, result.ASN, result.Org)
+	if err != nil {
+		return fmt.Errorf("sqlitestore: recording check for %s: %w", result.Proxy, err)
+	}
+	return nil
+}
+
+// History implements Store.
+func (s *SQLiteStore) History(ctx context.Context, filter HistoryFilter) ([]Check, error) {
+	query := strings.Builder{}
+	query.WriteString(`
+		SELECT proxy, scheme, checked_at, working, ip, error, duration_ms, anonymity, country, asn, org
+		FROM checks WHERE 1=1`)
+	var args []any
+
+	if !filter.Since.IsZero() {
+		query.WriteString(" AND checked_at >= ?")
+		args = append(args, filter.Since)
+	}
+	if filter.WorkingOnly {
+		query.WriteString(" AND working = 1")
+	}
+	if filter.Country != "" {
+		query.WriteString(" AND country = ? COLLATE NOCASE")
+		args = append(args, filter.Country)
+	}
+	query.WriteString(" ORDER BY checked_at DESC")
+
+	rows, err := s.db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlitestore: querying history: %w", err)
+	}
+	defer rows.Close()
+
+	var checks []Check
+	for rows.Next() {
+		check, durationMs, anonymity, err := scanCheck(rows)
+		if err != nil {
+			return nil, fmt.Errorf("sqlitestore: scanning history row: %w", err)
+		}
+		check.Duration = time.Duration(durationMs) * time.Millisecond
+		check.Anonymity = AnonymityLevel(anonymity)
+		checks = append(checks, check)
+	}
+	return checks, rows.Err()
+}
+
+// scanCheck reads one history row. The duration and anonymity columns are
+// returned separately from check since Check embeds Result by value and
+// Go has no addressable path into an embedded field's sibling types here.
+func scanCheck(rows *sql.Rows) (check Check, durationMs int64, anonymity string, err error) {
+	err = rows.Scan(&check.Proxy, &check.Scheme, &check.CheckedAt, &check.IsWorking, &check.IP,
+		&check.Error, &durationMs, &anonymity, &check.Country, &check.ASN, &check.Org)
+	return check, durationMs, anonymity, err
+}
+
+// StaleProxies implements Store.
+func (s *SQLiteStore) StaleProxies(ctx context.Context, olderThan time.Time) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT proxy FROM checks
+		GROUP BY proxy
+		HAVING MAX(checked_at) < ?`, olderThan)
+	if err != nil {
+		return nil, fmt.Errorf("sqlitestore: querying stale proxies: %w", err)
+	}
+	defer rows.Close()
+	return scanProxies(rows)
+}
+
+// ReliableProxies implements Store.
+func (s *SQLiteStore) ReliableProxies(ctx context.Context, minUptime float64) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT proxy FROM checks
+		GROUP BY proxy
+		HAVING AVG(CASE WHEN working THEN 1.0 ELSE 0.0 END) >= ?`, minUptime)
+	if err != nil {
+		return nil, fmt.Errorf("sqlitestore: querying reliable proxies: %w", err)
+	}
+	defer rows.Close()
+	return scanProxies(rows)
+}
+
+func scanProxies(rows *sql.Rows) ([]string, error) {
+	var proxies []string
+	for rows.Next() {
+		var proxy string
+		if err := rows.Scan(&proxy); err != nil {
+			return nil, err
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, rows.Err()
+}
+
+// Close implements Store.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
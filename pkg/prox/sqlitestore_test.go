@@ -0,0 +1,115 @@
+package prox
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "gep.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLiteStoreRecordAndHistoryRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	checks := []struct {
+		result    Result
+		checkedAt time.Time
+	}{
+		{Result{Proxy: "1.2.3.4:8080", Scheme: "http", IsWorking: true, Country: "DE"}, now.Add(-30 * time.Minute)},
+		{Result{Proxy: "5.6.7.8:1080", Scheme: "socks5", IsWorking: false, Error: "timeout", Country: "US"}, now.Add(-2 * time.Hour)},
+	}
+	for _, c := range checks {
+		if err := store.Record(ctx, c.result, c.checkedAt); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	all, err := store.History(ctx, HistoryFilter{})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("History returned %d checks, want 2", len(all))
+	}
+	if all[0].Proxy != "1.2.3.4:8080" {
+		t.Errorf("History[0].Proxy = %q, want most-recent-first order", all[0].Proxy)
+	}
+
+	recent, err := store.History(ctx, HistoryFilter{Since: now.Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("History with Since: %v", err)
+	}
+	if len(recent) != 1 || recent[0].Proxy != "1.2.3.4:8080" {
+		t.Fatalf("History with Since returned %+v, want only the 30-minute-old check", recent)
+	}
+
+	working, err := store.History(ctx, HistoryFilter{WorkingOnly: true})
+	if err != nil {
+		t.Fatalf("History with WorkingOnly: %v", err)
+	}
+	if len(working) != 1 || !working[0].IsWorking {
+		t.Fatalf("History with WorkingOnly returned %+v, want only the working check", working)
+	}
+
+	byCountry, err := store.History(ctx, HistoryFilter{Country: "us"})
+	if err != nil {
+		t.Fatalf("History with Country: %v", err)
+	}
+	if len(byCountry) != 1 || byCountry[0].Proxy != "5.6.7.8:1080" {
+		t.Fatalf("History with Country=us returned %+v, want the US check, case-insensitively", byCountry)
+	}
+}
+
+func TestSQLiteStoreStaleProxies(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	mustRecord(t, store, Result{Proxy: "fresh:1"}, now)
+	mustRecord(t, store, Result{Proxy: "stale:1"}, now.Add(-2*time.Hour))
+
+	stale, err := store.StaleProxies(ctx, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("StaleProxies: %v", err)
+	}
+	if len(stale) != 1 || stale[0] != "stale:1" {
+		t.Fatalf("StaleProxies = %v, want only stale:1", stale)
+	}
+}
+
+func TestSQLiteStoreReliableProxies(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	mustRecord(t, store, Result{Proxy: "reliable:1", IsWorking: true}, now)
+	mustRecord(t, store, Result{Proxy: "reliable:1", IsWorking: true}, now.Add(-time.Minute))
+	mustRecord(t, store, Result{Proxy: "flaky:1", IsWorking: true}, now)
+	mustRecord(t, store, Result{Proxy: "flaky:1", IsWorking: false}, now.Add(-time.Minute))
+
+	reliable, err := store.ReliableProxies(ctx, 0.8)
+	if err != nil {
+		t.Fatalf("ReliableProxies: %v", err)
+	}
+	if len(reliable) != 1 || reliable[0] != "reliable:1" {
+		t.Fatalf("ReliableProxies(0.8) = %v, want only reliable:1", reliable)
+	}
+}
+
+func mustRecord(t *testing.T, store *SQLiteStore, result Result, checkedAt time.Time) {
+	t.Helper()
+	if err := store.Record(context.Background(), result, checkedAt); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package prox
+
+import (
+	"context"
+	"time"
+)
+
+// Check is a single recorded Result, timestamped by a Store.
+type Check struct {
+	Result
+	CheckedAt time.Time
+}
+
+// HistoryFilter narrows a Store.History query. The zero value matches
+// everything.
+type HistoryFilter struct {
+	// Since, when non-zero, excludes checks recorded before it.
+	Since time.Time
+	// WorkingOnly, when true, excludes failed checks.
+	WorkingOnly bool
+	// Country, when non-empty, keeps only checks whose GeoIP country
+	// matches (case-insensitive).
+	Country string
+}
+
+// Store persists every proxy check gep performs, so results outlive a
+// single run. Implementations are free to back this with whatever they
+// like (SQLite, BoltDB, Postgres, ...); SQLiteStore is the default.
+type Store interface {
+	// Record saves one check, timestamped checkedAt.
+	Record(ctx context.Context, result Result, checkedAt time.Time) error
+	// History returns checks matching filter, most recent first.
+	History(ctx context.Context, filter HistoryFilter) ([]Check, error)
+	// StaleProxies returns every known proxy whose most recent check was
+	// before olderThan, for a recheck pass to re-test.
+	StaleProxies(ctx context.Context, olderThan time.Time) ([]string, error)
+	// ReliableProxies returns every known proxy whose fraction of
+	// working checks is at least minUptime, for db:// Loader queries.
+	ReliableProxies(ctx context.Context, minUptime float64) ([]string, error)
+	// Close releases any resources the Store holds open.
+	Close() error
+}
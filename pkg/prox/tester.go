@@ -0,0 +1,131 @@
+package prox
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Tester runs a Checker against a list of proxies, adapting how many run
+// concurrently to the target's apparent health.
+type Tester struct {
+	timeout    time.Duration
+	maxWorkers int
+	checker    Checker
+
+	// MinWorkers is the floor the AIMD controller will shrink
+	// concurrency to under sustained throttling. Defaults to 1 when
+	// zero. Read once, on the first TestProxies/TestProxiesStream call.
+	MinWorkers int
+	// Jitter, when positive, adds a random delay in [0, Jitter) before
+	// dispatching each check, so a large run doesn't hit its targets in
+	// lockstep.
+	Jitter time.Duration
+	// Metrics, when set, observes every check this Tester performs, via
+	// either TestProxies, TestProxiesStream, or a Revalidator running
+	// this Tester.
+	Metrics *Metrics
+
+	poolOnce sync.Once
+	pool     *adaptivePool
+}
+
+// NewTester creates a Tester that runs checker against up to maxWorkers
+// proxies at a time, each bounded by timeout. Concurrency starts at
+// maxWorkers and adapts from there; see Scoreboard.
+func NewTester(timeout time.Duration, maxWorkers int, checker Checker) *Tester {
+	return &Tester{
+		timeout:    timeout,
+		maxWorkers: maxWorkers,
+		checker:    checker,
+	}
+}
+
+// adaptive lazily creates the Tester's AIMD worker pool, so MinWorkers can
+// still be set after NewTester returns but before the first test run.
+func (t *Tester) adaptive() *adaptivePool {
+	t.poolOnce.Do(func() {
+		min := t.MinWorkers
+		if min <= 0 {
+			min = 1
+		}
+		max := t.maxWorkers
+		if max < min {
+			max = min
+		}
+		t.pool = newAdaptivePool(min, max)
+	})
+	return t.pool
+}
+
+// Scoreboard reports the current state of the Tester's adaptive worker
+// pool: its concurrency, in-flight checks, and recent outcome counts.
+func (t *Tester) Scoreboard() Scoreboard {
+	return t.adaptive().snapshot()
+}
+
+// TestProxies checks every proxy in proxies and returns once all checks have
+// completed. Order of the returned results is not guaranteed to match proxies.
+func (t *Tester) TestProxies(proxies []string) []Result {
+	allResults := make([]Result, 0, len(proxies))
+	for result := range t.TestProxiesStream(proxies) {
+		allResults = append(allResults, result)
+	}
+	return allResults
+}
+
+// TestProxiesStream checks every proxy in proxies and streams each Result
+// on the returned channel as soon as it is ready, instead of buffering the
+// whole batch like TestProxies. The channel is closed once every check has
+// completed. Order of the streamed results is not guaranteed to match
+// proxies.
+//
+// Concurrency is not fixed at maxWorkers: an AIMD controller (see
+// Scoreboard) grows it while checks keep succeeding at a stable latency,
+// and shrinks it immediately on a timeout or 5xx/429 burst, so a run
+// backs off a struggling target instead of hammering it at full
+// concurrency.
+func (t *Tester) TestProxiesStream(proxies []string) <-chan Result {
+	pool := t.adaptive()
+
+	jobs := make(chan string, len(proxies))
+	results := make(chan Result, t.maxWorkers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < t.maxWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for proxy := range jobs {
+				pool.acquire()
+
+				if t.Jitter > 0 {
+					time.Sleep(time.Duration(rand.Int63n(int64(t.Jitter))))
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+				result := t.checker.Check(ctx, proxy)
+				cancel()
+
+				pool.release(result)
+				if t.Metrics != nil {
+					t.Metrics.Observe(result)
+				}
+				results <- result
+			}
+		}()
+	}
+
+	for _, proxy := range proxies {
+		jobs <- proxy
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
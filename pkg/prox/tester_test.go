@@ -0,0 +1,45 @@
+package prox
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeChecker reports every proxy as working, so Tester tests only need to
+// exercise concurrency and wiring, not network behavior.
+type fakeChecker struct{}
+
+func (fakeChecker) Check(ctx context.Context, proxyURL string) Result {
+	return Result{Proxy: proxyURL, Scheme: "http", IsWorking: true, IP: "203.0.113.1"}
+}
+
+func TestTestProxiesStreamCoversEveryProxy(t *testing.T) {
+	proxies := []string{"a:1", "b:2", "c:3"}
+	tester := NewTester(0, 2, fakeChecker{})
+
+	seen := make(map[string]bool)
+	for result := range tester.TestProxiesStream(proxies) {
+		seen[result.Proxy] = true
+	}
+
+	for _, p := range proxies {
+		if !seen[p] {
+			t.Errorf("proxy %q was never streamed a result", p)
+		}
+	}
+}
+
+func TestTesterObservesMetrics(t *testing.T) {
+	metrics := NewMetrics()
+	tester := NewTester(0, 2, fakeChecker{})
+	tester.Metrics = metrics
+
+	tester.TestProxies([]string{"a:1", "b:2"})
+
+	metrics.mu.Lock()
+	count := metrics.checksTotal[checksKey{scheme: "http", result: "ok"}]
+	metrics.mu.Unlock()
+	if count != 2 {
+		t.Fatalf("checksTotal[http,ok] = %d, want 2", count)
+	}
+}